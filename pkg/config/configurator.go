@@ -25,7 +25,7 @@ func (p *ProductionConfigurator) WorkDir() string {
 func (p *ProductionConfigurator) UnmarshalKeyOnReload(key string, reloader invoker.Reloader, hook ...DecodeHookFunc) error {
 	if p.observer != nil {
 		p.observer.OnReload(func(ctx context.Context) {
-			_ = p.UnmarshalKey(key, reloader, hook...)
+			_ = p.unmarshalKey(key, reloader, true, hook...)
 			reloader.Reload(ctx)
 		})
 	}
@@ -34,6 +34,12 @@ func (p *ProductionConfigurator) UnmarshalKeyOnReload(key string, reloader invok
 
 // UnmarshalKey
 func (p *ProductionConfigurator) UnmarshalKey(key string, rawVal interface{}, hook ...DecodeHookFunc) error {
+	return p.unmarshalKey(key, rawVal, false, hook...)
+}
+
+// unmarshalKey decodes key into rawVal, maps, slices and pointers are allocated again on reload,
+// so copy of value kept by reloader for subscribers doesn't share them with value decoded by next reload
+func (p *ProductionConfigurator) unmarshalKey(key string, rawVal interface{}, reload bool, hook ...DecodeHookFunc) error {
 	mu.Lock()
 	defer mu.Unlock()
 	if e := bindValues(p.viper, p.initial.DisableBindMixedCapsEnv, rawVal, key); e != nil {
@@ -47,7 +53,9 @@ func (p *ProductionConfigurator) UnmarshalKey(key string, rawVal interface{}, ho
 		mapstructure.ComposeDecodeHookFunc(
 			hook...
 		),
-	))
+	), func(c *mapstructure.DecoderConfig) {
+		c.ZeroFields = reload
+	})
 }
 
 // NewProductionConfigurator
@@ -27,7 +27,7 @@ func (p *MockConfigurator) WorkDir() string {
 func (p *MockConfigurator) UnmarshalKeyOnReload(key string, reloader invoker.Reloader, hook ...DecodeHookFunc) error {
 	if p.observer != nil {
 		p.observer.OnReload(func(ctx context.Context) {
-			_ = p.UnmarshalKey(key, reloader, hook...)
+			_ = p.unmarshalKey(key, reloader, true, hook...)
 			reloader.Reload(ctx)
 		})
 	}
@@ -36,6 +36,12 @@ func (p *MockConfigurator) UnmarshalKeyOnReload(key string, reloader invoker.Rel
 
 // UnmarshalKey
 func (p *MockConfigurator) UnmarshalKey(key string, rawVal interface{}, hook ...DecodeHookFunc) error {
+	return p.unmarshalKey(key, rawVal, false, hook...)
+}
+
+// unmarshalKey decodes key into rawVal, maps, slices and pointers are allocated again on reload,
+// so copy of value kept by reloader for subscribers doesn't share them with value decoded by next reload
+func (p *MockConfigurator) unmarshalKey(key string, rawVal interface{}, reload bool, hook ...DecodeHookFunc) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if e := bindValues(p.viper, p.initial.DisableBindMixedCapsEnv, rawVal, key); e != nil {
@@ -49,7 +55,9 @@ func (p *MockConfigurator) UnmarshalKey(key string, rawVal interface{}, hook ...
 		mapstructure.ComposeDecodeHookFunc(
 			hook...
 		),
-	))
+	), func(c *mapstructure.DecoderConfig) {
+		c.ZeroFields = reload
+	})
 }
 
 // NewMockConfigurator
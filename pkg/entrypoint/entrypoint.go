@@ -131,6 +131,9 @@ func (e *EntryPoint) Shutdown(ctx context.Context, code int) {
 	if _, ok := ctx.Deadline(); ok {
 		<-ctx.Done()
 	}
+	if s, ok := e.set.Logger.(logger.Syncer); ok {
+		_ = s.Sync()
+	}
 	os.Exit(code)
 }
 
@@ -0,0 +1,113 @@
+package grpcinterceptor
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryClient returns client interceptor of unary calls
+func (i *Interceptors) UnaryClient() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if i.skip(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		ctx, span, c := i.startClient(ctx, method, TypeUnary, cc)
+		c.err = invoker(ctx, method, req, reply, cc, opts...)
+		i.finish(MetricClientScope, span, c)
+		return c.err
+	}
+}
+
+// StreamClient returns client interceptor of streams, call is finished when stream is received till end or failed
+func (i *Interceptors) StreamClient() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if i.skip(method) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		ctx, span, c := i.startClient(ctx, method, streamType(desc.ClientStreams, desc.ServerStreams), cc)
+		cs, e := streamer(ctx, desc, cc, method, opts...)
+		if e != nil {
+			c.err = e
+			i.finish(MetricClientScope, span, c)
+			return nil, e
+		}
+		return &clientStream{ClientStream: cs, serverStreams: desc.ServerStreams, finish: func(e error) {
+			c.err = e
+			i.finish(MetricClientScope, span, c)
+		}}, nil
+	}
+}
+
+// startClient starts span of call and injects it into outgoing metadata
+func (i *Interceptors) startClient(ctx context.Context, method, typ string, cc *grpc.ClientConn) (context.Context, opentracing.Span, *call) {
+	c := &call{fullMethod: method, typ: typ, peer: cc.Target(), start: time.Now()}
+	tracer := i.lmt.T()
+	opts := []opentracing.StartSpanOption{ext.SpanKindRPCClient}
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	span := tracer.StartSpan(method, opts...)
+	ext.Component.Set(span, Component)
+	// metadata of context is shared, so it's copied before injection
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	_ = tracer.Inject(span.Context(), opentracing.TextMap, metadataCarrier(md))
+	c.ctx = opentracing.ContextWithSpan(ctx, span)
+	return metadata.NewOutgoingContext(c.ctx, md), span, c
+}
+
+// clientStream is grpc.ClientStream finishes call on the end of stream or failure
+type clientStream struct {
+	grpc.ClientStream
+	// serverStreams is false if the only response is received, stream is done after it
+	serverStreams bool
+	once          sync.Once
+	finish        func(e error)
+}
+
+func (s *clientStream) done(e error) {
+	if e == io.EOF {
+		e = nil
+	}
+	s.once.Do(func() {
+		s.finish(e)
+	})
+}
+
+// Header returns the header metadata received from the server.
+func (s *clientStream) Header() (metadata.MD, error) {
+	md, e := s.ClientStream.Header()
+	if e != nil {
+		s.done(e)
+	}
+	return md, e
+}
+
+// SendMsg is generally called by generated code.
+func (s *clientStream) SendMsg(m interface{}) error {
+	e := s.ClientStream.SendMsg(m)
+	if e != nil && e != io.EOF {
+		s.done(e)
+	}
+	return e
+}
+
+// RecvMsg blocks until it receives a message into m or the stream is done.
+func (s *clientStream) RecvMsg(m interface{}) error {
+	e := s.ClientStream.RecvMsg(m)
+	if e != nil || !s.serverStreams {
+		s.done(e)
+	}
+	return e
+}
@@ -0,0 +1,126 @@
+// Package grpcinterceptor provides grpc server and client interceptors records metrics, propagates spans and logs calls
+package grpcinterceptor
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric"
+	"github.com/uber-go/tally"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	Prefix = "go-core.instrumentation.grpc"
+
+	MetricServerScope = "grpc.server"
+	MetricClientScope = "grpc.client"
+	MetricRequests    = "requests"
+	MetricLatency     = "latency"
+	MetricTagService  = "service"
+	MetricTagMethod   = "method"
+	MetricTagType     = "type"
+	MetricTagCode     = "code"
+
+	TypeUnary        = "unary"
+	TypeClientStream = "client_stream"
+	TypeServerStream = "server_stream"
+	TypeBidiStream   = "bidi_stream"
+
+	FieldService  = "grpc.service"
+	FieldMethod   = "grpc.method"
+	FieldType     = "grpc.type"
+	FieldCode     = "grpc.code"
+	FieldPeer     = "peer"
+	FieldDuration = "duration"
+	FieldStack    = "stack"
+	FieldPanic    = "panic"
+
+	// Component is a value of component tag of spans
+	Component = "gRPC"
+	// PanicMessage is a message of status of calls recovered from panic, the panic value isn't sent to client
+	PanicMessage = "internal error"
+)
+
+// DefaultBuckets are buckets of latency histograms
+var DefaultBuckets = tally.MustMakeExponentialDurationBuckets(time.Millisecond, 2, 16)
+
+type options struct {
+	buckets metric.Buckets
+	filter  func(fullMethod string) bool
+}
+
+// Option is func hook for underling logic call
+type Option func(*options) error
+
+// WithBuckets returns func hook sets buckets of latency histograms
+func WithBuckets(buckets metric.Buckets) Option {
+	return func(o *options) error {
+		o.buckets = buckets
+		return nil
+	}
+}
+
+// WithFilter returns func hook skips instrumentation of calls aren't matched by fn, e.g. health checks
+func WithFilter(fn func(fullMethod string) bool) Option {
+	return func(o *options) error {
+		o.filter = fn
+		return nil
+	}
+}
+
+// splitMethod returns service and method of full method name, e.g. /grpc.health.v1.Health/Check
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
+
+// streamType returns type of call by directions of streaming
+func streamType(client, server bool) string {
+	switch {
+	case client && server:
+		return TypeBidiStream
+	case client:
+		return TypeClientStream
+	case server:
+		return TypeServerStream
+	}
+	return TypeUnary
+}
+
+// levelOf returns level of log of call by code, failures of server are logged as errors
+func levelOf(code codes.Code) logger.Level {
+	switch code {
+	case codes.OK:
+		return logger.LevelInfo
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return logger.LevelError
+	}
+	return logger.LevelWarning
+}
+
+// metadataCarrier is opentracing carrier of grpc metadata
+type metadataCarrier metadata.MD
+
+// Set implements opentracing.TextMapWriter, keys of metadata are lowercase
+func (c metadataCarrier) Set(key, val string) {
+	key = strings.ToLower(key)
+	c[key] = append(c[key], val)
+}
+
+// ForeachKey implements opentracing.TextMapReader
+func (c metadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, vs := range c {
+		for _, v := range vs {
+			if e := handler(k, v); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
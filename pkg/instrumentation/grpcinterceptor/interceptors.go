@@ -0,0 +1,98 @@
+package grpcinterceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptors are grpc interceptors use logger, metric scope and tracer of LMT
+type Interceptors struct {
+	lmt  provider.LMT
+	log  logger.Logger
+	opts options
+}
+
+// New returns interceptors use logger, metric scope and tracer of lmt
+func New(lmt provider.LMT, opts ...Option) (*Interceptors, error) {
+	i := &Interceptors{
+		lmt:  lmt,
+		log:  lmt.L().WithFields(logger.Fields{"service": Prefix}),
+		opts: options{buckets: DefaultBuckets},
+	}
+	for _, opt := range opts {
+		if e := opt(&i.opts); e != nil {
+			return nil, errors.WithMessage(e, Prefix)
+		}
+	}
+	return i, nil
+}
+
+// ServerOptions returns options of server with unary and stream interceptors
+func (i *Interceptors) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(i.UnaryServer()),
+		grpc.StreamInterceptor(i.StreamServer()),
+	}
+}
+
+// DialOptions returns options of client connection with unary and stream interceptors
+func (i *Interceptors) DialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithUnaryInterceptor(i.UnaryClient()),
+		grpc.WithStreamInterceptor(i.StreamClient()),
+	}
+}
+
+// skip returns true if call isn't instrumented
+func (i *Interceptors) skip(fullMethod string) bool {
+	return i.opts.filter != nil && !i.opts.filter(fullMethod)
+}
+
+// call is a result of served or sent call
+type call struct {
+	ctx        context.Context
+	fullMethod string
+	typ        string
+	peer       string
+	start      time.Time
+	err        error
+}
+
+// finish reports metrics and log of call and finishes its span
+func (i *Interceptors) finish(scope string, span opentracing.Span, c *call, opts ...logger.Option) {
+	duration := time.Since(c.start)
+	code := status.Code(c.err)
+	service, method := splitMethod(c.fullMethod)
+	tagged := i.lmt.M().SubScope(scope).Tagged(map[string]string{
+		MetricTagService: service,
+		MetricTagMethod:  method,
+		MetricTagType:    c.typ,
+		MetricTagCode:    code.String(),
+	})
+	tagged.Counter(MetricRequests).Inc(1)
+	tagged.Histogram(MetricLatency, i.opts.buckets).RecordDuration(duration)
+
+	span.SetTag(FieldCode, code.String())
+	if c.err != nil {
+		ext.Error.Set(span, true)
+	}
+	fields := logger.Fields{
+		FieldService:  service,
+		FieldMethod:   method,
+		FieldType:     c.typ,
+		FieldCode:     code.String(),
+		FieldPeer:     c.peer,
+		FieldDuration: duration.String(),
+	}
+	opts = append(opts, logger.Args(scope, c.fullMethod, code), logger.WithFields(fields), logger.Err(c.err), logger.WithContext(c.ctx))
+	i.log.Log(levelOf(code), "%s: %s %s", opts...)
+	span.Finish()
+}
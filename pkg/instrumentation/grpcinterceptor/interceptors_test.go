@@ -0,0 +1,165 @@
+package grpcinterceptor
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric/metrictest"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	health "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// healthServer fails or panics by name of requested service
+type healthServer struct {
+	t *testing.T
+}
+
+func (s *healthServer) Check(ctx context.Context, req *health.HealthCheckRequest) (*health.HealthCheckResponse, error) {
+	if opentracing.SpanFromContext(ctx) == nil {
+		s.t.Error("span isn't passed to handler")
+	}
+	switch req.Service {
+	case "panic":
+		panic("check failed")
+	case "missing":
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &health.HealthCheckResponse{Status: health.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *health.HealthCheckRequest, stream health.Health_WatchServer) error {
+	if req.Service == "panic" {
+		panic("watch failed")
+	}
+	for i := 0; i < 2; i++ {
+		if e := stream.Send(&health.HealthCheckResponse{Status: health.HealthCheckResponse_SERVING}); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+func TestInterceptors(t *testing.T) {
+	scope := metrictest.NewScope("", nil)
+	tracer := mocktracer.New()
+	log := loggertest.NewRecorder(nil)
+	i, e := New(&provider.AwareSet{Logger: log, Metric: scope, Tracer: tracer})
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(i.ServerOptions()...)
+	health.RegisterHealthServer(server, &healthServer{t: t})
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	ctx := context.Background()
+	opts := append(i.DialOptions(), grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	conn, e := grpc.DialContext(ctx, "bufnet", opts...)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer conn.Close()
+	client := health.NewHealthClient(conn)
+
+	parent := tracer.StartSpan("parent")
+	ctx = opentracing.ContextWithSpan(ctx, parent)
+	if _, e := client.Check(ctx, &health.HealthCheckRequest{}); e != nil {
+		t.Fatal(e)
+	}
+	if _, e := client.Check(ctx, &health.HealthCheckRequest{Service: "missing"}); status.Code(e) != codes.NotFound {
+		t.Fatalf("unexpected error %v", e)
+	}
+	_, e = client.Check(ctx, &health.HealthCheckRequest{Service: "panic"})
+	if s, _ := status.FromError(e); s.Code() != codes.Internal || s.Message() != PanicMessage {
+		t.Fatalf("panic isn't recovered into internal error: %v", e)
+	}
+	stream, e := client.Watch(ctx, &health.HealthCheckRequest{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	for {
+		if _, e := stream.Recv(); e == io.EOF {
+			break
+		} else if e != nil {
+			t.Fatal(e)
+		}
+	}
+	stream, e = client.Watch(ctx, &health.HealthCheckRequest{Service: "panic"})
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, e := stream.Recv(); status.Code(e) != codes.Internal {
+		t.Fatalf("panic of stream isn't recovered into internal error: %v", e)
+	}
+	parent.Finish()
+	server.GracefulStop()
+
+	tags := func(method, typ string, code codes.Code) map[string]string {
+		return map[string]string{
+			MetricTagService: "grpc.health.v1.Health",
+			MetricTagMethod:  method,
+			MetricTagType:    typ,
+			MetricTagCode:    code.String(),
+		}
+	}
+	for _, prefix := range []string{MetricServerScope, MetricClientScope} {
+		scope.AssertCounter(t, prefix+"."+MetricRequests, tags("Check", TypeUnary, codes.OK), 1)
+		scope.AssertCounter(t, prefix+"."+MetricRequests, tags("Check", TypeUnary, codes.NotFound), 1)
+		scope.AssertCounter(t, prefix+"."+MetricRequests, tags("Check", TypeUnary, codes.Internal), 1)
+		scope.AssertCounter(t, prefix+"."+MetricRequests, tags("Watch", TypeServerStream, codes.OK), 1)
+		scope.AssertCounter(t, prefix+"."+MetricRequests, tags("Watch", TypeServerStream, codes.Internal), 1)
+		latency := scope.Capture().DurationHistogram(prefix+"."+MetricLatency, tags("Check", TypeUnary, codes.OK))
+		var samples int64
+		for _, n := range latency {
+			samples += n
+		}
+		if samples != 1 {
+			t.Errorf("latency of %s isn't recorded", prefix)
+		}
+	}
+
+	// spans of server are children of spans of client
+	spans := tracer.FinishedSpans()
+	byID := map[int]*mocktracer.MockSpan{}
+	for _, span := range spans {
+		byID[span.SpanContext.SpanID] = span
+	}
+	servers := 0
+	for _, span := range spans {
+		if span.Tag(string(ext.SpanKind)) != ext.SpanKindRPCServerEnum {
+			continue
+		}
+		servers++
+		client, ok := byID[span.ParentID]
+		if !ok || client.Tag(string(ext.SpanKind)) != ext.SpanKindRPCClientEnum || client.ParentID != parent.(*mocktracer.MockSpan).SpanContext.SpanID {
+			t.Errorf("span %s isn't child of client span", span.OperationName)
+		}
+	}
+	if servers != 5 {
+		t.Errorf("expected 5 server spans, got %d", servers)
+	}
+
+	loggertest.AssertLogged(t, log, loggertest.ByLevel(logger.LevelInfo), loggertest.ByField(FieldMethod, "Check"),
+		loggertest.HasField(FieldPeer), loggertest.ByField(FieldCode, "OK"))
+	loggertest.AssertLogged(t, log, loggertest.ByLevel(logger.LevelWarning), loggertest.ByField(FieldCode, "NotFound"))
+	loggertest.AssertLogged(t, log, loggertest.ByLevel(logger.LevelError), loggertest.ByField(FieldPanic, "check failed"),
+		loggertest.HasField(FieldStack), loggertest.ByMessage("^grpc.server: "))
+	loggertest.AssertLogged(t, log, loggertest.ByField(FieldPanic, "watch failed"))
+}
@@ -0,0 +1,16 @@
+package grpcinterceptor
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/google/wire"
+)
+
+// Provider returns grpc interceptors with resolved dependencies
+func Provider(lmt provider.LMT) (*Interceptors, func(), error) {
+	i, e := New(lmt)
+	return i, func() {}, e
+}
+
+var (
+	WireSet = wire.NewSet(Provider)
+)
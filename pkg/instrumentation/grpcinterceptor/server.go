@@ -0,0 +1,91 @@
+package grpcinterceptor
+
+import (
+	"context"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServer returns server interceptor of unary calls, panics of handler are recovered into codes.Internal
+func (i *Interceptors) UnaryServer() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		if i.skip(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, span, c := i.startServer(ctx, info.FullMethod, TypeUnary)
+		var opts []logger.Option
+		defer func() {
+			if p := recover(); p != nil {
+				opts, err = i.recovered(p)
+			}
+			c.err = err
+			i.finish(MetricServerScope, span, c, opts...)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServer returns server interceptor of streams, panics of handler are recovered into codes.Internal
+func (i *Interceptors) StreamServer() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if i.skip(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, span, c := i.startServer(ss.Context(), info.FullMethod, streamType(info.IsClientStream, info.IsServerStream))
+		var opts []logger.Option
+		defer func() {
+			if p := recover(); p != nil {
+				opts, err = i.recovered(p)
+			}
+			c.err = err
+			i.finish(MetricServerScope, span, c, opts...)
+		}()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// startServer starts span of call extracted from incoming metadata
+func (i *Interceptors) startServer(ctx context.Context, fullMethod, typ string) (context.Context, opentracing.Span, *call) {
+	c := &call{fullMethod: fullMethod, typ: typ, start: time.Now()}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		c.peer = p.Addr.String()
+	}
+	tracer := i.lmt.T()
+	opts := []opentracing.StartSpanOption{ext.SpanKindRPCServer}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if parent, e := tracer.Extract(opentracing.TextMap, metadataCarrier(md)); e == nil {
+			opts = append(opts, opentracing.ChildOf(parent))
+		}
+	}
+	span := tracer.StartSpan(fullMethod, opts...)
+	ext.Component.Set(span, Component)
+	c.ctx = opentracing.ContextWithSpan(ctx, span)
+	return c.ctx, span, c
+}
+
+// recovered returns options of log with panic value and stack and status of call recovered from panic
+func (i *Interceptors) recovered(p interface{}) ([]logger.Option, error) {
+	return []logger.Option{
+		logger.Stack(FieldStack),
+		logger.WithPrettyFields(logger.Fields{FieldPanic: p}),
+	}, status.Error(codes.Internal, PanicMessage)
+}
+
+// serverStream is grpc.ServerStream with context holds span of call
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context for this stream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,65 @@
+package nethttp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+// transport is http.RoundTripper instruments requests sent by underlying transport
+type transport struct {
+	i    *Instrumentation
+	base http.RoundTripper
+}
+
+// Transport returns round tripper sends requests by base with metrics, span injected into headers and access logs,
+// http.DefaultTransport is used if base is nil, route template of request is set by WithRoute
+func (i *Instrumentation) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{i: i, base: base}
+}
+
+// RoundTrip executes a single HTTP transaction.
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.i.skip(r) {
+		return t.base.RoundTrip(r)
+	}
+	start := time.Now()
+	route := t.i.route(r, clientRouteKey{})
+	opts := []opentracing.StartSpanOption{ext.SpanKindRPCClient}
+	if parent := opentracing.SpanFromContext(r.Context()); parent != nil {
+		opts = append(opts, opentracing.ChildOf(parent.Context()))
+	}
+	span := t.i.tracer.StartSpan("HTTP "+methodTag(r.Method)+" "+route, opts...)
+	defer span.Finish()
+	ext.Component.Set(span, Component)
+	ext.HTTPMethod.Set(span, r.Method)
+	ext.HTTPUrl.Set(span, r.URL.String())
+	span.SetTag(TagRoute, route)
+
+	// request mustn't be modified by round tripper, so headers are copied before injection
+	out := new(http.Request)
+	*out = *r
+	out.Header = make(http.Header, len(r.Header))
+	for k, v := range r.Header {
+		out.Header[k] = append([]string(nil), v...)
+	}
+	_ = t.i.tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(out.Header))
+
+	resp, e := t.base.RoundTrip(out)
+	q := &request{r: r, route: route, size: -1, duration: time.Since(start), err: e}
+	if resp != nil {
+		q.status = resp.StatusCode
+		q.size = resp.ContentLength
+		ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+	}
+	if q.failed() {
+		ext.Error.Set(span, true)
+	}
+	t.i.observe(MetricClientScope, q)
+	return resp, e
+}
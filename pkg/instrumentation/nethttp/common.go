@@ -0,0 +1,86 @@
+// Package nethttp provides instrumentation of net/http servers and clients by metrics, tracing and access logs
+package nethttp
+
+import (
+	"net/http"
+)
+
+const (
+	Prefix       = "go-core.instrumentation.nethttp"
+	UnmarshalKey = "instrumentation.http"
+
+	MetricServerScope = "http.server"
+	MetricClientScope = "http.client"
+	MetricRequests    = "requests"
+	MetricErrors      = "errors"
+	MetricDuration    = "duration"
+	MetricTagMethod   = "method"
+	MetricTagRoute    = "route"
+	MetricTagStatus   = "status"
+
+	// RouteUnknown is route of requests without route template, raw paths aren't used to keep cardinality of metrics
+	RouteUnknown = "unknown"
+	// MethodOther is method tag of requests with non-standard methods
+	MethodOther = "other"
+	// StatusError is status of client requests failed without response
+	StatusError = "error"
+	// Component is a value of component tag of spans
+	Component = "net/http"
+	// TagRoute is a tag of span with route template
+	TagRoute = "http.route"
+
+	FieldMethod     = "method"
+	FieldRoute      = "route"
+	FieldPath       = "path"
+	FieldHost       = "host"
+	FieldStatus     = "status"
+	FieldDuration   = "duration"
+	FieldSize       = "size"
+	FieldRemoteAddr = "remote_addr"
+	FieldUserAgent  = "user_agent"
+	FieldRequestID  = "request_id"
+	FieldError      = "error"
+
+	HeaderRequestID = "X-Request-Id"
+)
+
+// DefaultFields are fields of access log entries if fields aren't configured
+var DefaultFields = []string{FieldMethod, FieldRoute, FieldStatus, FieldDuration, FieldSize}
+
+// AccessLogCfg is a setting of access logs of requests
+type AccessLogCfg struct {
+	Enabled bool
+	// Fields are names of fields of entry, default fields are used if not set
+	Fields []string
+	// SampleRate is a share of logged requests from 0 to 1, failed requests are always logged
+	SampleRate float64 `default:"1"`
+}
+
+// Config is a general settings of http instrumentation
+type Config struct {
+	AccessLog AccessLogCfg
+}
+
+type options struct {
+	route  func(r *http.Request) string
+	filter func(r *http.Request) bool
+}
+
+// Option is func hook for underling logic call
+type Option func(*options) error
+
+// WithRouteFunc returns func hook resolves route template of request if it isn't set by Route or WithRoute
+func WithRouteFunc(fn func(r *http.Request) string) Option {
+	return func(o *options) error {
+		o.route = fn
+		return nil
+	}
+}
+
+// WithFilter returns func hook skips instrumentation of requests aren't matched by fn, e.g. health checks
+func WithFilter(fn func(r *http.Request) bool) Option {
+	return func(o *options) error {
+		o.filter = fn
+		return nil
+	}
+}
@@ -0,0 +1,197 @@
+package nethttp
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric"
+	"github.com/ProtocolONE/go-core/v2/pkg/tracing"
+	"github.com/pkg/errors"
+)
+
+// Instrumentation wraps http handlers and transports by metrics, tracing and access logs
+type Instrumentation struct {
+	log    logger.Logger
+	scope  metric.Scope
+	tracer tracing.Tracer
+	cfg    AccessLogCfg
+	opts   options
+}
+
+// New returns instrumentation uses logger, metric scope and tracer, e.g. ones of entrypoint
+func New(log logger.Logger, scope metric.Scope, tracer tracing.Tracer, cfg *Config, opts ...Option) (*Instrumentation, error) {
+	i := &Instrumentation{
+		log:    log.WithFields(logger.Fields{"service": Prefix}),
+		scope:  scope,
+		tracer: tracer,
+		cfg:    cfg.AccessLog,
+	}
+	if len(i.cfg.Fields) == 0 {
+		i.cfg.Fields = DefaultFields
+	}
+	for _, opt := range opts {
+		if e := opt(&i.opts); e != nil {
+			return nil, errors.WithMessage(e, Prefix)
+		}
+	}
+	return i, nil
+}
+
+// serverRouteKey and clientRouteKey are separate, so client requests sent with context of served request
+// don't get its route
+type (
+	serverRouteKey struct{}
+	clientRouteKey struct{}
+)
+
+// routeHolder keeps route template of request, it's set by handler of request
+type routeHolder struct {
+	route string
+}
+
+// WithRoute returns context with route template for client requests, e.g. /users/{id}
+func WithRoute(ctx context.Context, template string) context.Context {
+	return context.WithValue(ctx, clientRouteKey{}, &routeHolder{route: template})
+}
+
+// SetRoute sets route template of request served by instrumented handler, e.g. by router
+func SetRoute(r *http.Request, template string) {
+	if h, ok := r.Context().Value(serverRouteKey{}).(*routeHolder); ok {
+		h.route = template
+	}
+}
+
+// Route returns handler sets route template of requests served by h
+func Route(template string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRoute(r, template)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// route returns route template of request by key of server or client route
+func (i *Instrumentation) route(r *http.Request, key interface{}) string {
+	if h, ok := r.Context().Value(key).(*routeHolder); ok && h.route != "" {
+		return h.route
+	}
+	if i.opts.route != nil {
+		if route := i.opts.route(r); route != "" {
+			return route
+		}
+	}
+	return RouteUnknown
+}
+
+// methodTag returns method of request for metrics and span names, non-standard methods are replaced by MethodOther
+func methodTag(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return MethodOther
+}
+
+// skip returns true if request isn't instrumented
+func (i *Instrumentation) skip(r *http.Request) bool {
+	return i.opts.filter != nil && !i.opts.filter(r)
+}
+
+// request is result of served or sent request, size of response is negative if it's unknown, e.g. chunked response
+type request struct {
+	r        *http.Request
+	route    string
+	status   int
+	size     int64
+	duration time.Duration
+	err      error
+}
+
+// failed returns true if request is failed by server or transport
+func (q *request) failed() bool {
+	return q.err != nil || q.status >= http.StatusInternalServerError
+}
+
+// statusTag returns value of status tag of metrics
+func (q *request) statusTag() string {
+	if q.err != nil {
+		return StatusError
+	}
+	return strconv.Itoa(q.status)
+}
+
+// observe reports RED metrics and access log of request
+func (i *Instrumentation) observe(scope string, q *request) {
+	tagged := i.scope.SubScope(scope).Tagged(map[string]string{
+		MetricTagMethod: methodTag(q.r.Method),
+		MetricTagRoute:  q.route,
+		MetricTagStatus: q.statusTag(),
+	})
+	tagged.Counter(MetricRequests).Inc(1)
+	if q.failed() {
+		tagged.Counter(MetricErrors).Inc(1)
+	}
+	tagged.Timer(MetricDuration).Record(q.duration)
+	i.accessLog(scope, q)
+}
+
+// accessLog writes entry of request if it's sampled, failed requests are always logged
+func (i *Instrumentation) accessLog(scope string, q *request) {
+	if !i.cfg.Enabled {
+		return
+	}
+	failed := q.failed()
+	if !failed && (i.cfg.SampleRate <= 0 || i.cfg.SampleRate < 1 && rand.Float64() >= i.cfg.SampleRate) {
+		return
+	}
+	fields := make(logger.Fields, len(i.cfg.Fields))
+	for _, name := range i.cfg.Fields {
+		if v, ok := q.field(name); ok {
+			fields[name] = v
+		}
+	}
+	level := logger.LevelInfo
+	if failed {
+		level = logger.LevelError
+		if q.err != nil {
+			fields[FieldError] = q.err.Error()
+		}
+	}
+	i.log.Log(level, "%s: %s %s %s", logger.Args(scope, q.r.Method, q.route, q.statusTag()),
+		logger.WithFields(fields), logger.WithContext(q.r.Context()))
+}
+
+// field returns value of access log field by name
+func (q *request) field(name string) (interface{}, bool) {
+	switch name {
+	case FieldMethod:
+		return q.r.Method, true
+	case FieldRoute:
+		return q.route, true
+	case FieldPath:
+		return q.r.URL.Path, true
+	case FieldHost:
+		if q.r.Host != "" {
+			return q.r.Host, true
+		}
+		return q.r.URL.Host, true
+	case FieldStatus:
+		return q.status, true
+	case FieldDuration:
+		return q.duration.String(), true
+	case FieldSize:
+		return q.size, q.size >= 0
+	case FieldRemoteAddr:
+		return q.r.RemoteAddr, q.r.RemoteAddr != ""
+	case FieldUserAgent:
+		return q.r.UserAgent(), true
+	case FieldRequestID:
+		id := q.r.Header.Get(HeaderRequestID)
+		return id, id != ""
+	}
+	return nil, false
+}
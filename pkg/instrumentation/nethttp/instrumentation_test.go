@@ -0,0 +1,192 @@
+package nethttp
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric/metrictest"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+func TestInstrumentation(t *testing.T) {
+	scope := metrictest.NewScope("", nil)
+	tracer := mocktracer.New()
+	log := loggertest.NewRecorder(nil)
+	cfg := &Config{AccessLog: AccessLogCfg{Enabled: true, SampleRate: 0, Fields: []string{FieldMethod, FieldPath, FieldStatus}}}
+	i, e := New(log, scope, tracer, cfg, WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/health"
+	}))
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/users/", Route("/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opentracing.SpanFromContext(r.Context()) == nil {
+			t.Error("span isn't passed to handler")
+		}
+		if r.URL.Path == "/users/0" {
+			http.Error(w, "failed", http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})))
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(i.Handler(mux))
+	defer server.Close()
+
+	client := &http.Client{Transport: i.Transport(nil)}
+	parent := tracer.StartSpan("parent")
+	ctx := WithRoute(opentracing.ContextWithSpan(context.Background(), parent), "/users/{id}")
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/health"} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		resp, e := client.Do(req.WithContext(ctx))
+		if e != nil {
+			t.Fatal(e)
+		}
+		_, _ = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+	parent.Finish()
+
+	ok := map[string]string{MetricTagMethod: http.MethodGet, MetricTagRoute: "/users/{id}", MetricTagStatus: "200"}
+	failed := map[string]string{MetricTagMethod: http.MethodGet, MetricTagRoute: "/users/{id}", MetricTagStatus: "500"}
+	for _, prefix := range []string{MetricServerScope, MetricClientScope} {
+		scope.AssertCounter(t, prefix+"."+MetricRequests, ok, 2)
+		scope.AssertCounter(t, prefix+"."+MetricRequests, failed, 1)
+		scope.AssertCounter(t, prefix+"."+MetricErrors, failed, 1)
+		scope.AssertTimer(t, prefix+"."+MetricDuration, ok, 2)
+	}
+	if c := scope.Capture().Counter(MetricServerScope+"."+MetricRequests, map[string]string{
+		MetricTagMethod: http.MethodGet, MetricTagRoute: RouteUnknown, MetricTagStatus: "200",
+	}); c != 0 {
+		t.Errorf("filtered request is instrumented")
+	}
+
+	// each server span is child of client span is child of parent span
+	spans := tracer.FinishedSpans()
+	byID := map[int]*mocktracer.MockSpan{}
+	for _, span := range spans {
+		byID[span.SpanContext.SpanID] = span
+	}
+	servers := 0
+	for _, span := range spans {
+		if span.Tag(string(ext.SpanKind)) != ext.SpanKindRPCServerEnum {
+			continue
+		}
+		servers++
+		client, ok := byID[span.ParentID]
+		if !ok || client.Tag(string(ext.SpanKind)) != ext.SpanKindRPCClientEnum || client.ParentID != parent.(*mocktracer.MockSpan).SpanContext.SpanID {
+			t.Errorf("span %s isn't child of client span", span.OperationName)
+		}
+		if span.OperationName != "HTTP GET /users/{id}" {
+			t.Errorf("unexpected operation name %s", span.OperationName)
+		}
+	}
+	if servers != 3 {
+		t.Errorf("expected 3 server spans, got %d", servers)
+	}
+
+	// only failed requests are logged with zero sample rate
+	loggertest.AssertLogged(t, log, loggertest.ByLevel(logger.LevelError), loggertest.ByField(FieldPath, "/users/0"),
+		loggertest.ByField(FieldStatus, 500), loggertest.ByMessage("^http.server: GET /users/\\{id\\} 500$"))
+	loggertest.AssertNotLogged(t, log, loggertest.ByField(FieldStatus, 200))
+	if n := len(log.Filter(loggertest.HasField(FieldMethod))); n != 2 {
+		t.Errorf("expected access logs of server and client, got %d", n)
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	scope := metrictest.NewScope("", nil)
+	log := loggertest.NewRecorder(nil)
+	i, e := New(log, scope, mocktracer.New(), &Config{AccessLog: AccessLogCfg{Enabled: true, SampleRate: 1}})
+	if e != nil {
+		t.Fatal(e)
+	}
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := &http.Client{Transport: i.Transport(nil)}
+	if _, e := client.Get(server.URL); e == nil {
+		t.Fatal("request to closed server is sent")
+	}
+	tags := map[string]string{MetricTagMethod: http.MethodGet, MetricTagRoute: RouteUnknown, MetricTagStatus: StatusError}
+	scope.AssertCounter(t, MetricClientScope+"."+MetricErrors, tags, 1)
+	loggertest.AssertLogged(t, log, loggertest.ByLevel(logger.LevelError), loggertest.HasField(FieldError))
+}
+
+func TestRouteOfNestedClient(t *testing.T) {
+	scope := metrictest.NewScope("", nil)
+	i, e := New(loggertest.NewRecorder(nil), scope, mocktracer.New(), &Config{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer backend.Close()
+
+	client := &http.Client{Transport: i.Transport(nil)}
+	server := httptest.NewServer(i.Handler(Route("/orders/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// request to backend is sent with context of served request
+		req, _ := http.NewRequest("PURGE", backend.URL+"/cache", nil)
+		resp, e := client.Do(req.WithContext(r.Context()))
+		if e != nil {
+			t.Error(e)
+			return
+		}
+		_ = resp.Body.Close()
+	}))))
+	defer server.Close()
+
+	resp, e := http.Get(server.URL + "/orders/1")
+	if e != nil {
+		t.Fatal(e)
+	}
+	_ = resp.Body.Close()
+
+	scope.AssertCounter(t, MetricServerScope+"."+MetricRequests,
+		map[string]string{MetricTagMethod: http.MethodGet, MetricTagRoute: "/orders/{id}", MetricTagStatus: "200"}, 1)
+	scope.AssertCounter(t, MetricClientScope+"."+MetricRequests,
+		map[string]string{MetricTagMethod: MethodOther, MetricTagRoute: RouteUnknown, MetricTagStatus: "200"}, 1)
+}
+
+func TestResponseWriter(t *testing.T) {
+	log := loggertest.NewRecorder(nil)
+	cfg := &Config{AccessLog: AccessLogCfg{Enabled: true, SampleRate: 1, Fields: []string{FieldSize}}}
+	i, e := New(log, metrictest.NewScope("", nil), mocktracer.New(), cfg)
+	if e != nil {
+		t.Fatal(e)
+	}
+	server := httptest.NewServer(i.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(http.Pusher); !ok {
+			t.Error("http.Pusher isn't passed through")
+		}
+		if _, ok := w.(io.ReaderFrom); !ok {
+			t.Error("io.ReaderFrom isn't passed through")
+		}
+		// flushed response is chunked, so its length is unknown by client
+		w.(http.Flusher).Flush()
+		_, _ = io.Copy(w, strings.NewReader("hello"))
+	})))
+	defer server.Close()
+
+	client := &http.Client{Transport: i.Transport(nil)}
+	resp, e := client.Get(server.URL)
+	if e != nil {
+		t.Fatal(e)
+	}
+	_, _ = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	loggertest.AssertLogged(t, log, loggertest.ByMessage("^http.server"), loggertest.ByField(FieldSize, int64(5)))
+	loggertest.AssertLogged(t, log, loggertest.ByMessage("^http.client"))
+	loggertest.AssertNotLogged(t, log, loggertest.ByMessage("^http.client"), loggertest.HasField(FieldSize))
+}
@@ -0,0 +1,26 @@
+package nethttp
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/config"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric"
+	"github.com/ProtocolONE/go-core/v2/pkg/tracing"
+	"github.com/google/wire"
+)
+
+// ProviderCfg returns configuration of http instrumentation
+func ProviderCfg(cfg config.Configurator) (*Config, func(), error) {
+	c := &Config{}
+	e := cfg.UnmarshalKey(UnmarshalKey, c)
+	return c, func() {}, e
+}
+
+// Provider returns http instrumentation with resolved dependencies
+func Provider(log logger.Logger, scope metric.Scope, tracer tracing.Tracer, cfg *Config) (*Instrumentation, func(), error) {
+	i, e := New(log, scope, tracer, cfg)
+	return i, func() {}, e
+}
+
+var (
+	WireSet = wire.NewSet(Provider, ProviderCfg)
+)
@@ -0,0 +1,128 @@
+package nethttp
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+)
+
+// ErrHijackNotSupported is returned on hijack of connection if underlying writer doesn't support it
+var ErrHijackNotSupported = errors.New("http.Hijacker is not supported by response writer")
+
+// Handler returns handler serves requests by h with metrics, span extracted from headers and access logs
+func (i *Instrumentation) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if i.skip(r) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		opts := []opentracing.StartSpanOption{ext.SpanKindRPCServer}
+		if parent, e := i.tracer.Extract(opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(r.Header)); e == nil {
+			opts = append(opts, opentracing.ChildOf(parent))
+		}
+		span := i.tracer.StartSpan("HTTP "+methodTag(r.Method), opts...)
+		ext.Component.Set(span, Component)
+		ext.HTTPMethod.Set(span, r.Method)
+		ext.HTTPUrl.Set(span, r.URL.String())
+		ctx := opentracing.ContextWithSpan(r.Context(), span)
+		ctx = context.WithValue(ctx, serverRouteKey{}, &routeHolder{})
+		r = r.WithContext(ctx)
+
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			q := &request{r: r, route: i.route(r, serverRouteKey{}), status: sw.status, size: sw.size, duration: time.Since(start)}
+			if p := recover(); p != nil {
+				q.status = http.StatusInternalServerError
+				i.finish(span, q)
+				panic(p)
+			}
+			if q.status == 0 {
+				q.status = http.StatusOK
+			}
+			i.finish(span, q)
+		}()
+		h.ServeHTTP(sw, r)
+	})
+}
+
+// finish reports metrics of served request and finishes its span
+func (i *Instrumentation) finish(span opentracing.Span, q *request) {
+	span.SetOperationName("HTTP " + methodTag(q.r.Method) + " " + q.route)
+	span.SetTag(TagRoute, q.route)
+	ext.HTTPStatusCode.Set(span, uint16(q.status))
+	if q.failed() {
+		ext.Error.Set(span, true)
+	}
+	i.observe(MetricServerScope, q)
+	span.Finish()
+}
+
+// statusWriter keeps status and size of response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WriteHeader sends an HTTP response header with the provided status code.
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes the data to the connection as part of an HTTP reply.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, e := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, e
+}
+
+// Flush sends any buffered data to the client.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets the caller take over the connection.
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, ErrHijackNotSupported
+}
+
+// Push initiates an HTTP/2 server push.
+func (w *statusWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// ReadFrom writes data of r as response body, io.ReaderFrom of underlying writer is used, e.g. for sendfile.
+func (w *statusWriter) ReadFrom(r io.Reader) (n int64, e error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, e = rf.ReadFrom(r)
+	} else {
+		n, e = io.Copy(w.ResponseWriter, r)
+	}
+	w.size += n
+	return n, e
+}
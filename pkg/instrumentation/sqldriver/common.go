@@ -0,0 +1,135 @@
+// Package sqldriver provides database/sql/driver wrapper records metrics, spans and slow queries of calls
+package sqldriver
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric"
+	"github.com/uber-go/tally"
+)
+
+const (
+	Prefix       = "go-core.instrumentation.sql"
+	UnmarshalKey = "instrumentation.sql"
+
+	MetricScope        = "sql"
+	MetricPoolScope    = "pool"
+	MetricDuration     = "duration"
+	MetricErrors       = "errors"
+	MetricTagOperation = "operation"
+	MetricTagDB        = "db"
+
+	MetricPoolMaxOpen           = "max_open"
+	MetricPoolOpen              = "open"
+	MetricPoolInUse             = "in_use"
+	MetricPoolIdle              = "idle"
+	MetricPoolWaitCount         = "wait_count"
+	MetricPoolWaitDuration      = "wait_duration"
+	MetricPoolMaxIdleClosed     = "max_idle_closed"
+	MetricPoolMaxLifetimeClosed = "max_lifetime_closed"
+
+	OperationQuery    = "query"
+	OperationExec     = "exec"
+	OperationPrepare  = "prepare"
+	OperationBegin    = "begin"
+	OperationCommit   = "commit"
+	OperationRollback = "rollback"
+	OperationPing     = "ping"
+
+	FieldOperation = "operation"
+	FieldStatement = "statement"
+	FieldDuration  = "duration"
+	FieldDB        = "db"
+	FieldError     = "error"
+
+	// Component is a value of component tag of spans
+	Component = "database/sql"
+	// DBType is a value of db.type tag of spans
+	DBType = "sql"
+
+	DefaultStatsInterval = 10 * time.Second
+)
+
+// DefaultBuckets are buckets of duration histograms
+var DefaultBuckets = tally.MustMakeExponentialDurationBuckets(time.Millisecond, 2, 16)
+
+var (
+	literalString = regexp.MustCompile(`'(?:[^']|'')*'`)
+	// numbers are matched out of identifiers and positional placeholders, e.g. users2 or $1
+	literalNumber = regexp.MustCompile(`(^|[^\w$.])[0-9]+(?:\.[0-9]+)?\b`)
+)
+
+// Config is a general settings of sql instrumentation, SlowThreshold and Sanitize are applied on reload
+type Config struct {
+	// SlowThreshold is a duration of calls are logged as slow, slow calls aren't logged if it isn't set
+	SlowThreshold time.Duration
+	// Sanitize replaces literals of statements in spans and logs by placeholders
+	Sanitize bool
+	// StatsInterval is an interval of reporting of stats of connection pool, default interval is used if not set
+	StatsInterval time.Duration
+	invoker       *invoker.Invoker
+	// snapshot is a copy of config taken on reload, subscribers read it while config is decoded by next reload
+	snapshot atomic.Value
+}
+
+// OnReload
+func (c *Config) OnReload(callback func(ctx context.Context)) {
+	c.invoker.OnReload(callback)
+}
+
+// Reload
+func (c *Config) Reload(ctx context.Context) {
+	snapshot := *c
+	c.snapshot.Store(&snapshot)
+	c.invoker.Reload(ctx)
+}
+
+// loaded returns copy of config taken on the last reload, config itself is returned before reload
+func (c *Config) loaded() *Config {
+	if s, ok := c.snapshot.Load().(*Config); ok {
+		return s
+	}
+	return c
+}
+
+type options struct {
+	name     string
+	buckets  metric.Buckets
+	sanitize func(query string) string
+}
+
+// Option is func hook for underling logic call
+type Option func(*options) error
+
+// WithName returns func hook sets name of database, it's used as tag of metrics and spans
+func WithName(name string) Option {
+	return func(o *options) error {
+		o.name = name
+		return nil
+	}
+}
+
+// WithBuckets returns func hook sets buckets of duration histograms
+func WithBuckets(buckets metric.Buckets) Option {
+	return func(o *options) error {
+		o.buckets = buckets
+		return nil
+	}
+}
+
+// WithSanitizer returns func hook sets sanitizer of statements used instead of default one if sanitizing is enabled
+func WithSanitizer(fn func(query string) string) Option {
+	return func(o *options) error {
+		o.sanitize = fn
+		return nil
+	}
+}
+
+// Sanitize replaces string and number literals of statement by placeholders
+func Sanitize(query string) string {
+	return literalNumber.ReplaceAllString(literalString.ReplaceAllString(query, "?"), "${1}?")
+}
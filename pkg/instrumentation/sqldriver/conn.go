@@ -0,0 +1,223 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// conn is driver.Conn records calls of underlying connection,
+// optional interfaces which aren't implemented by underlying connection are skipped to fallback of database/sql
+type conn struct {
+	driver.Conn
+	i *instrumentation
+}
+
+// Prepare returns a prepared statement, bound to this connection.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext returns a prepared statement, bound to this connection.
+func (c *conn) PrepareContext(ctx context.Context, query string) (s driver.Stmt, err error) {
+	finish := c.i.start(ctx, OperationPrepare, query)
+	defer func() {
+		finish(err)
+	}()
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = p.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	st := &stmt{Stmt: s, conn: c.Conn, query: query, i: c.i}
+	if _, ok := s.(driver.ColumnConverter); ok {
+		return &converterStmt{stmt: st}, nil
+	}
+	return st, nil
+}
+
+// Begin starts and returns a new transaction.
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx starts and returns a new transaction.
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (t driver.Tx, err error) {
+	finish := c.i.start(ctx, OperationBegin, "")
+	defer func() {
+		finish(err)
+	}()
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		t, err = b.BeginTx(ctx, opts)
+	} else {
+		t, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t, ctx: ctx, i: c.i}, nil
+}
+
+// ExecContext executes a query that doesn't return rows.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (r driver.Result, err error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	finish := c.i.start(ctx, OperationExec, query)
+	defer func() {
+		finish(err)
+	}()
+	return e.ExecContext(ctx, query, args)
+}
+
+// QueryContext executes a query that may return rows.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (r driver.Rows, err error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	finish := c.i.start(ctx, OperationQuery, query)
+	defer func() {
+		finish(err)
+	}()
+	return q.QueryContext(ctx, query, args)
+}
+
+// Ping verifies a connection to the database is still alive.
+func (c *conn) Ping(ctx context.Context) (err error) {
+	p, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	finish := c.i.start(ctx, OperationPing, "")
+	defer func() {
+		finish(err)
+	}()
+	return p.Ping(ctx)
+}
+
+// ResetSession is called prior to executing a query on the connection if the connection has been used before.
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue is called before passing arguments to the driver.
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// stmt is driver.Stmt records executions of underlying statement
+type stmt struct {
+	driver.Stmt
+	conn  driver.Conn
+	query string
+	i     *instrumentation
+}
+
+// Exec executes a query that doesn't return rows.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+// Query executes a query that may return rows.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+// ExecContext executes a query that doesn't return rows.
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (r driver.Result, err error) {
+	finish := s.i.start(ctx, OperationExec, s.query)
+	defer func() {
+		finish(err)
+	}()
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+	return s.Stmt.Exec(values(args))
+}
+
+// QueryContext executes a query that may return rows.
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (r driver.Rows, err error) {
+	finish := s.i.start(ctx, OperationQuery, s.query)
+	defer func() {
+		finish(err)
+	}()
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return q.QueryContext(ctx, args)
+	}
+	return s.Stmt.Query(values(args))
+}
+
+// CheckNamedValue is called before passing arguments to the driver, checker of connection is used if statement hasn't it.
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	if n, ok := s.conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// converterStmt is stmt of underlying statement implements driver.ColumnConverter,
+// other statements don't implement it, so database/sql keeps own order of fallbacks for them
+type converterStmt struct {
+	*stmt
+}
+
+// ColumnConverter returns a ValueConverter of underlying statement
+func (s *converterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return s.Stmt.(driver.ColumnConverter).ColumnConverter(idx)
+}
+
+// tx is driver.Tx records commit and rollback of underlying transaction
+type tx struct {
+	driver.Tx
+	ctx context.Context
+	i   *instrumentation
+}
+
+// Commit commits the transaction.
+func (t *tx) Commit() (err error) {
+	finish := t.i.start(t.ctx, OperationCommit, "")
+	defer func() {
+		finish(err)
+	}()
+	return t.Tx.Commit()
+}
+
+// Rollback aborts the transaction.
+func (t *tx) Rollback() (err error) {
+	finish := t.i.start(t.ctx, OperationRollback, "")
+	defer func() {
+		finish(err)
+	}()
+	return t.Tx.Rollback()
+}
+
+// namedValues converts values of legacy interfaces into named values
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return named
+}
+
+// values converts named values for legacy interfaces, names of values are dropped
+func values(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i, nv := range args {
+		vs[i] = nv.Value
+	}
+	return vs
+}
@@ -0,0 +1,19 @@
+//go:build go1.15
+// +build go1.15
+
+package sqldriver
+
+import (
+	"database/sql/driver"
+)
+
+var _ driver.Validator = (*conn)(nil)
+
+// IsValid is called prior to placing the connection into the connection pool,
+// connection is valid if underlying connection doesn't implement driver.Validator
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
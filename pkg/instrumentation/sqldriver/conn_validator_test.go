@@ -0,0 +1,36 @@
+//go:build go1.15
+// +build go1.15
+
+package sqldriver
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric/metrictest"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+// invalidConn is connection reports it can't be reused
+type invalidConn struct {
+	fakeConn
+}
+
+func (c *invalidConn) IsValid() bool {
+	return false
+}
+
+func TestConnIsValid(t *testing.T) {
+	lmt := &provider.AwareSet{Logger: loggertest.NewRecorder(nil), Metric: metrictest.NewScope("", nil), Tracer: mocktracer.New()}
+	i, e := newInstrumentation(lmt, &Config{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	for c, expected := range map[driver.Conn]bool{&fakeConn{}: true, &invalidConn{}: false} {
+		if valid := (&conn{Conn: c, i: i}).IsValid(); valid != expected {
+			t.Errorf("validity of %T is %v, expected %v", c, valid, expected)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync/atomic"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/pkg/errors"
+)
+
+// instrumentation records metrics, spans and slow calls of wrapped driver
+type instrumentation struct {
+	lmt provider.LMT
+	log logger.Logger
+	// cfg keeps copy of config, it's replaced on reload
+	cfg  atomic.Value
+	opts options
+}
+
+func newInstrumentation(lmt provider.LMT, cfg *Config, opts ...Option) (*instrumentation, error) {
+	i := &instrumentation{
+		lmt:  lmt,
+		log:  lmt.L().WithFields(logger.Fields{"service": Prefix}),
+		opts: options{buckets: DefaultBuckets, sanitize: Sanitize},
+	}
+	for _, opt := range opts {
+		if e := opt(&i.opts); e != nil {
+			return nil, errors.WithMessage(e, Prefix)
+		}
+	}
+	i.cfg.Store(*cfg)
+	if cfg.invoker != nil {
+		cfg.OnReload(func(_ context.Context) {
+			i.cfg.Store(*cfg.loaded())
+		})
+	}
+	return i, nil
+}
+
+func (i *instrumentation) load() Config {
+	return i.cfg.Load().(Config)
+}
+
+// start starts call of operation, returned func finishes it with result of call
+func (i *instrumentation) start(ctx context.Context, operation, query string) func(err error) {
+	start := time.Now()
+	cfg := i.load()
+	var span opentracing.Span
+	if parent := opentracing.SpanFromContext(ctx); parent != nil {
+		span = i.lmt.T().StartSpan(MetricScope+" "+operation, opentracing.ChildOf(parent.Context()), ext.SpanKindRPCClient)
+		ext.Component.Set(span, Component)
+		ext.DBType.Set(span, DBType)
+		if i.opts.name != "" {
+			ext.DBInstance.Set(span, i.opts.name)
+		}
+		if query != "" {
+			ext.DBStatement.Set(span, i.statement(cfg, query))
+		}
+	}
+	return func(err error) {
+		// call is passed to fallback of database/sql, so it isn't recorded
+		if err == driver.ErrSkip {
+			if span != nil {
+				span.Finish()
+			}
+			return
+		}
+		duration := time.Since(start)
+		tagged := i.lmt.M().SubScope(MetricScope).Tagged(map[string]string{
+			MetricTagOperation: operation,
+			MetricTagDB:        i.opts.name,
+		})
+		tagged.Histogram(MetricDuration, i.opts.buckets).RecordDuration(duration)
+		failed := err != nil && err != sql.ErrTxDone
+		if failed {
+			tagged.Counter(MetricErrors).Inc(1)
+		}
+		if cfg.SlowThreshold > 0 && duration >= cfg.SlowThreshold {
+			i.log.Warning("slow %s call of database: %s", logger.Args(operation, duration), logger.WithFields(logger.Fields{
+				FieldOperation: operation,
+				FieldStatement: i.statement(cfg, query),
+				FieldDuration:  duration.String(),
+				FieldDB:        i.opts.name,
+			}), logger.WithContext(ctx))
+		}
+		if span != nil {
+			if failed {
+				ext.Error.Set(span, true)
+				span.SetTag(FieldError, err.Error())
+			}
+			span.Finish()
+		}
+	}
+}
+
+// statement returns statement for spans and logs, it's sanitized if it's enabled
+func (i *instrumentation) statement(cfg Config, query string) string {
+	if cfg.Sanitize {
+		return i.opts.sanitize(query)
+	}
+	return query
+}
+
+// Driver is driver.Driver records metrics, spans and slow calls of underlying driver
+type Driver struct {
+	driver.Driver
+	i *instrumentation
+}
+
+// Wrap returns driver instruments calls of d by logger, metric scope and tracer of lmt
+func Wrap(d driver.Driver, lmt provider.LMT, cfg *Config, opts ...Option) (*Driver, error) {
+	i, e := newInstrumentation(lmt, cfg, opts...)
+	if e != nil {
+		return nil, e
+	}
+	return &Driver{Driver: d, i: i}, nil
+}
+
+// Open returns a new connection to the database.
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	c, e := d.Driver.Open(name)
+	if e != nil {
+		return nil, e
+	}
+	return &conn{Conn: c, i: d.i}, nil
+}
+
+// OpenConnector returns connector of underlying driver by name, name is passed to Open if driver doesn't support connectors
+func (d *Driver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		c, e := dc.OpenConnector(name)
+		if e != nil {
+			return nil, e
+		}
+		return &connector{Connector: c, driver: d}, nil
+	}
+	return &connector{Connector: dsnConnector{name: name, driver: d.Driver}, driver: d}, nil
+}
+
+// Open returns database uses wrapped driver d, stats of connection pool are reported till context is done
+func Open(ctx context.Context, d driver.Driver, name string, lmt provider.LMT, cfg *Config, opts ...Option) (*sql.DB, error) {
+	wrapped, e := Wrap(d, lmt, cfg, opts...)
+	if e != nil {
+		return nil, e
+	}
+	c, e := wrapped.OpenConnector(name)
+	if e != nil {
+		return nil, errors.WithMessage(e, Prefix)
+	}
+	db := sql.OpenDB(c)
+	go CollectStats(ctx, db, lmt.M(), cfg.StatsInterval, wrapped.i.opts.name)
+	return db, nil
+}
+
+// connector is driver.Connector returns instrumented connections
+type connector struct {
+	driver.Connector
+	driver *Driver
+}
+
+// Connect returns a connection to the database.
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, e := c.Connector.Connect(ctx)
+	if e != nil {
+		return nil, e
+	}
+	return &conn{Conn: cn, i: c.driver.i}, nil
+}
+
+// Driver returns the underlying Driver of the Connector.
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dsnConnector is connector of driver doesn't support connectors
+type dsnConnector struct {
+	name   string
+	driver driver.Driver
+}
+
+// Connect returns a connection to the database.
+func (c dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+// Driver returns the underlying Driver of the Connector.
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
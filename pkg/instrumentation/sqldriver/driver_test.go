@@ -0,0 +1,283 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+	"github.com/ProtocolONE/go-core/v2/pkg/metric/metrictest"
+	"github.com/ProtocolONE/go-core/v2/pkg/provider"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/opentracing/opentracing-go/mocktracer"
+)
+
+var errFake = errors.New("fake failure")
+
+// fakeDriver is in-memory driver, statements with sleep are slow and statements with fail are failed
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e := run(query); e != nil {
+		return nil, e
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeStmt struct {
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if e := run(s.query); e != nil {
+		return nil, e
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if e := run(s.query); e != nil {
+		return nil, e
+	}
+	return &fakeRows{}, nil
+}
+
+func run(query string) error {
+	if strings.Contains(query, "sleep") {
+		time.Sleep(30 * time.Millisecond)
+	}
+	if strings.Contains(query, "fail") {
+		return errFake
+	}
+	return nil
+}
+
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return nil
+}
+
+func TestDriver(t *testing.T) {
+	scope := metrictest.NewScope("", nil)
+	tracer := mocktracer.New()
+	log := loggertest.NewRecorder(nil)
+	lmt := &provider.AwareSet{Logger: log, Metric: scope, Tracer: tracer}
+	cfg := &Config{SlowThreshold: 20 * time.Millisecond, Sanitize: true, StatsInterval: 10 * time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, e := Open(ctx, fakeDriver{}, "memory", lmt, cfg, WithName("users"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer db.Close()
+
+	parent := tracer.StartSpan("parent")
+	ctx = opentracing.ContextWithSpan(ctx, parent)
+	if _, e := db.ExecContext(ctx, "INSERT INTO users VALUES ('bob', 42, $1)", 1); e != nil {
+		t.Fatal(e)
+	}
+	var id int64
+	if e := db.QueryRowContext(ctx, "SELECT id FROM users WHERE name = 'bob'").Scan(&id); e != nil || id != 1 {
+		t.Fatalf("unexpected result %d: %v", id, e)
+	}
+	if _, e := db.ExecContext(ctx, "SELECT sleep(1)"); e != nil {
+		t.Fatal(e)
+	}
+	if _, e := db.ExecContext(ctx, "SELECT fail()"); e != errFake {
+		t.Fatalf("unexpected error %v", e)
+	}
+	tx, e := db.BeginTx(ctx, nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if e := tx.Commit(); e != nil {
+		t.Fatal(e)
+	}
+	parent.Finish()
+
+	samples := func(operation string) int64 {
+		var n int64
+		histogram := scope.Capture().DurationHistogram(MetricScope+"."+MetricDuration, map[string]string{
+			MetricTagOperation: operation,
+			MetricTagDB:        "users",
+		})
+		for _, v := range histogram {
+			n += v
+		}
+		return n
+	}
+	for operation, expected := range map[string]int64{
+		OperationExec:    3,
+		OperationPrepare: 1,
+		OperationQuery:   1,
+		OperationBegin:   1,
+		OperationCommit:  1,
+	} {
+		if n := samples(operation); n != expected {
+			t.Errorf("expected %d calls of %s, got %d", expected, operation, n)
+		}
+	}
+	scope.AssertCounter(t, MetricScope+"."+MetricErrors, map[string]string{MetricTagOperation: OperationExec, MetricTagDB: "users"}, 1)
+
+	statements := map[string]bool{}
+	for _, span := range tracer.FinishedSpans() {
+		if span.ParentID != parent.(*mocktracer.MockSpan).SpanContext.SpanID {
+			continue
+		}
+		if statement, ok := span.Tag(string(ext.DBStatement)).(string); ok {
+			statements[statement] = true
+		}
+		if span.Tag(string(ext.DBInstance)) != "users" {
+			t.Errorf("span %s has no instance", span.OperationName)
+		}
+	}
+	for _, statement := range []string{"INSERT INTO users VALUES (?, ?, $1)", "SELECT id FROM users WHERE name = ?", "SELECT sleep(?)"} {
+		if !statements[statement] {
+			t.Errorf("span of statement %s isn't found in %v", statement, statements)
+		}
+	}
+
+	loggertest.AssertLogged(t, log, loggertest.ByLevel(logger.LevelWarning), loggertest.ByField(FieldStatement, "SELECT sleep(?)"))
+	if n := len(log.Filter(loggertest.ByLevel(logger.LevelWarning))); n != 1 {
+		t.Errorf("expected one slow call, got %d", n)
+	}
+
+	tags := map[string]string{MetricTagDB: "users"}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if open, ok := scope.Capture().Gauge(MetricScope+"."+MetricPoolScope+"."+MetricPoolOpen, tags); ok && open == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("stats of pool aren't reported")
+		}
+	}
+}
+
+func TestDriverReload(t *testing.T) {
+	log := loggertest.NewRecorder(nil)
+	lmt := &provider.AwareSet{Logger: log, Metric: metrictest.NewScope("", nil), Tracer: mocktracer.New()}
+	cfg := &Config{invoker: invoker.NewInvoker()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db, e := Open(ctx, fakeDriver{}, "memory", lmt, cfg)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer db.Close()
+
+	if _, e := db.ExecContext(ctx, "SELECT sleep(1)"); e != nil {
+		t.Fatal(e)
+	}
+	loggertest.AssertNotLogged(t, log, loggertest.ByLevel(logger.LevelWarning))
+
+	cfg.SlowThreshold, cfg.Sanitize = 20*time.Millisecond, true
+	cfg.Reload(ctx)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, e := db.ExecContext(ctx, "SELECT sleep(2)"); e != nil {
+			t.Fatal(e)
+		}
+		if len(log.Filter(loggertest.ByLevel(logger.LevelWarning), loggertest.ByField(FieldStatement, "SELECT sleep(?)"))) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("slow threshold and sanitizing aren't reloaded")
+		}
+	}
+}
+
+// converterConn prepares statements implement driver.ColumnConverter
+type converterConn struct {
+	fakeConn
+}
+
+func (c *converterConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeConverterStmt{fakeStmt{query: query}}, nil
+}
+
+type fakeConverterStmt struct {
+	fakeStmt
+}
+
+func (s *fakeConverterStmt) ColumnConverter(idx int) driver.ValueConverter {
+	return driver.Int32
+}
+
+func TestStmtColumnConverter(t *testing.T) {
+	lmt := &provider.AwareSet{Logger: loggertest.NewRecorder(nil), Metric: metrictest.NewScope("", nil), Tracer: mocktracer.New()}
+	i, e := newInstrumentation(lmt, &Config{})
+	if e != nil {
+		t.Fatal(e)
+	}
+	for c, expected := range map[driver.Conn]bool{&fakeConn{}: false, &converterConn{}: true} {
+		s, e := (&conn{Conn: c, i: i}).Prepare("SELECT 1")
+		if e != nil {
+			t.Fatal(e)
+		}
+		cc, ok := s.(driver.ColumnConverter)
+		if ok != expected {
+			t.Errorf("statement of %T implements driver.ColumnConverter: %v, expected %v", c, ok, expected)
+		}
+		if ok && cc.ColumnConverter(0) != driver.Int32 {
+			t.Error("converter of underlying statement isn't used")
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package sqldriver
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/config"
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/google/wire"
+)
+
+// ProviderCfg returns configuration of sql instrumentation
+func ProviderCfg(cfg config.Configurator) (*Config, func(), error) {
+	c := &Config{
+		invoker: invoker.NewInvoker(),
+	}
+	e := cfg.UnmarshalKeyOnReload(UnmarshalKey, c)
+	return c, func() {}, e
+}
+
+var (
+	WireSet = wire.NewSet(ProviderCfg)
+)
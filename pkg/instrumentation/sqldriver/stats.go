@@ -0,0 +1,39 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/metric"
+)
+
+// CollectStats reports stats of connection pool of db as gauges till context is done,
+// default interval is used if interval isn't set
+func CollectStats(ctx context.Context, db *sql.DB, scope metric.Scope, interval time.Duration, name string) {
+	if interval <= 0 {
+		interval = DefaultStatsInterval
+	}
+	pool := scope.SubScope(MetricScope).SubScope(MetricPoolScope).Tagged(map[string]string{MetricTagDB: name})
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		reportStats(pool, db.Stats())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func reportStats(scope metric.Scope, s sql.DBStats) {
+	scope.Gauge(MetricPoolMaxOpen).Update(float64(s.MaxOpenConnections))
+	scope.Gauge(MetricPoolOpen).Update(float64(s.OpenConnections))
+	scope.Gauge(MetricPoolInUse).Update(float64(s.InUse))
+	scope.Gauge(MetricPoolIdle).Update(float64(s.Idle))
+	scope.Gauge(MetricPoolWaitCount).Update(float64(s.WaitCount))
+	scope.Gauge(MetricPoolWaitDuration).Update(s.WaitDuration.Seconds())
+	scope.Gauge(MetricPoolMaxIdleClosed).Update(float64(s.MaxIdleClosed))
+	scope.Gauge(MetricPoolMaxLifetimeClosed).Update(float64(s.MaxLifetimeClosed))
+}
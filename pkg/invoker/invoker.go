@@ -30,24 +30,18 @@ func (i *Invoker) init() {
 	}
 }
 
-// OnReload
+// OnReload calls callback on every reload raised after return
 func (i *Invoker) OnReload(callback func(ctx context.Context)) {
-	i.init()
+	dispatch, closeCh := i.subscribe()
 	go func() {
-		// fmt.Println("run on reload")
-		// defer fmt.Println("stop on reload")
 		for {
-			i.mu.Lock()
-			dispatch := i.dispatch
-			closeCh := i.closeCh
-			reloadCh := dispatch.reloadCh
-			i.mu.Unlock()
 			select {
 			case <-closeCh:
 				return
-			case <-reloadCh:
+			case <-dispatch.reloadCh:
 				i.mu.Lock()
 				ctx := dispatch.ctx
+				dispatch = i.dispatch
 				i.mu.Unlock()
 				callback(ctx)
 			}
@@ -55,6 +49,15 @@ func (i *Invoker) OnReload(callback func(ctx context.Context)) {
 	}()
 }
 
+// subscribe returns current dispatcher and close channel, listener is subscribed before its goroutine is started,
+// otherwise reload raised right after OnReload can be lost
+func (i *Invoker) subscribe() (*Dispatcher, chan struct{}) {
+	i.init()
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.dispatch, i.closeCh
+}
+
 // Reload
 func (i *Invoker) Reload(ctx context.Context) {
 	i.init()
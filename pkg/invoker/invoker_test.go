@@ -3,6 +3,7 @@ package invoker
 import (
 	"context"
 	"testing"
+	"time"
 )
 
 func TestClose(t *testing.T) {
@@ -17,3 +18,40 @@ func TestReload(t *testing.T) {
 	})
 	inv.Reload(context.Background())
 }
+
+func TestReloadRightAfterSubscribe(t *testing.T) {
+	inv := NewInvoker()
+	done := make(chan struct{})
+	inv.OnReload(func(_ context.Context) {
+		close(done)
+	})
+	inv.Reload(context.Background())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reload is lost")
+	}
+}
+
+func TestReloadSubscribers(t *testing.T) {
+	type key struct{}
+	inv := NewInvoker()
+	defer inv.Close()
+	values := make(chan interface{}, 2)
+	for i := 0; i < 2; i++ {
+		inv.OnReload(func(ctx context.Context) {
+			values <- ctx.Value(key{})
+		})
+	}
+	inv.Reload(context.WithValue(context.Background(), key{}, "reload"))
+	for i := 0; i < 2; i++ {
+		select {
+		case v := <-values:
+			if v != "reload" {
+				t.Errorf("unexpected context of reload: %v", v)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("reload is lost")
+		}
+	}
+}
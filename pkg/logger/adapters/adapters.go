@@ -0,0 +1,34 @@
+// Package adapters provides adapters from logger.Logger to interfaces of standard and third-party libraries,
+// tags, fields and name of the wrapped logger are carried through
+package adapters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+)
+
+// pairs returns fields of key/value pairs, values without string key are added under logger.FieldBadKey
+func pairs(kv []interface{}) logger.Fields {
+	fields := make(logger.Fields, (len(kv)+1)/2)
+	for i := 0; i < len(kv); {
+		key, ok := kv[i].(string)
+		if !ok || i+1 == len(kv) {
+			fields[logger.FieldBadKey] = kv[i]
+			i++
+			continue
+		}
+		fields[key] = kv[i+1]
+		i += 2
+	}
+	return fields
+}
+
+// sprint returns message of args like fmt.Sprint/fmt.Sprintln without trailing new line
+func sprint(ln bool, args []interface{}) string {
+	if ln {
+		return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+	}
+	return fmt.Sprint(args...)
+}
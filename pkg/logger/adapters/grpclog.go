@@ -0,0 +1,97 @@
+package adapters
+
+import (
+	"os"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"google.golang.org/grpc/grpclog"
+)
+
+// exit is called by fatal methods of grpclog.LoggerV2
+var exit = os.Exit
+
+// Grpc is adapter to grpclog.LoggerV2, e.g. grpclog.SetLoggerV2(adapters.NewGrpc(log, 0))
+type Grpc struct {
+	logger    logger.Logger
+	verbosity int
+}
+
+var _ grpclog.LoggerV2 = (*Grpc)(nil)
+
+// NewGrpc returns adapter writes entries into l, V(level) returns true for levels up to verbosity
+func NewGrpc(l logger.Logger, verbosity int) *Grpc {
+	return &Grpc{logger: l, verbosity: verbosity}
+}
+
+// Info logs to INFO log
+func (g *Grpc) Info(args ...interface{}) {
+	g.logger.Info(sprint(false, args))
+}
+
+// Infoln logs to INFO log
+func (g *Grpc) Infoln(args ...interface{}) {
+	g.logger.Info(sprint(true, args))
+}
+
+// Infof logs to INFO log
+func (g *Grpc) Infof(format string, args ...interface{}) {
+	g.logger.Info(format, logger.Args(args...))
+}
+
+// Warning logs to WARNING log
+func (g *Grpc) Warning(args ...interface{}) {
+	g.logger.Warning(sprint(false, args))
+}
+
+// Warningln logs to WARNING log
+func (g *Grpc) Warningln(args ...interface{}) {
+	g.logger.Warning(sprint(true, args))
+}
+
+// Warningf logs to WARNING log
+func (g *Grpc) Warningf(format string, args ...interface{}) {
+	g.logger.Warning(format, logger.Args(args...))
+}
+
+// Error logs to ERROR log
+func (g *Grpc) Error(args ...interface{}) {
+	g.logger.Error(sprint(false, args))
+}
+
+// Errorln logs to ERROR log
+func (g *Grpc) Errorln(args ...interface{}) {
+	g.logger.Error(sprint(true, args))
+}
+
+// Errorf logs to ERROR log
+func (g *Grpc) Errorf(format string, args ...interface{}) {
+	g.logger.Error(format, logger.Args(args...))
+}
+
+// Fatal logs with critical level and exits with status 1
+func (g *Grpc) Fatal(args ...interface{}) {
+	g.fatal(sprint(false, args))
+}
+
+// Fatalln logs with critical level and exits with status 1
+func (g *Grpc) Fatalln(args ...interface{}) {
+	g.fatal(sprint(true, args))
+}
+
+// Fatalf logs with critical level and exits with status 1
+func (g *Grpc) Fatalf(format string, args ...interface{}) {
+	g.fatal(format, logger.Args(args...))
+}
+
+func (g *Grpc) fatal(format string, opts ...logger.Option) {
+	g.logger.Critical(format, opts...)
+	if s, ok := g.logger.(logger.Syncer); ok {
+		_ = s.Sync()
+	}
+	exit(1)
+}
+
+// V reports whether verbosity level l is at least the requested verbose level.
+func (g *Grpc) V(l int) bool {
+	return l <= g.verbosity
+}
@@ -0,0 +1,32 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+)
+
+func TestGrpc(t *testing.T) {
+	var code int
+	prev := exit
+	exit = func(c int) { code = c }
+	defer func() { exit = prev }()
+
+	r := loggertest.NewRecorder(nil)
+	g := NewGrpc(r.Named("grpc"), 1)
+	g.Infoln("channel", "created")
+	g.Warningf("retry %d", 2)
+	g.Error("transport", " closed")
+	g.Fatal("bind")
+	if !g.V(1) || g.V(2) {
+		t.Errorf("unexpected verbosity")
+	}
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelInfo), loggertest.ByMessage(`^channel created$`), loggertest.ByName("grpc"))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelWarning), loggertest.ByMessage(`^retry 2$`))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelError), loggertest.ByMessage(`^transport closed$`))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelCritical), loggertest.ByMessage(`^bind$`))
+	if code != 1 {
+		t.Errorf("expected exit with code 1, got %d", code)
+	}
+}
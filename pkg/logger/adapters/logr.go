@@ -0,0 +1,56 @@
+package adapters
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/go-logr/logr"
+)
+
+// logrSink is logr.LogSink writes entries into logger, V(0) is mapped to info level and others to debug
+type logrSink struct {
+	logger logger.Logger
+}
+
+// NewLogr returns logr.Logger writes entries into l
+func NewLogr(l logger.Logger) logr.Logger {
+	return logr.New(&logrSink{logger: l})
+}
+
+// Init receives optional information about the logr library
+func (s *logrSink) Init(logr.RuntimeInfo) {}
+
+// logrLevel returns RFC5424 level of V-level
+func logrLevel(level int) logger.Level {
+	if level > 0 {
+		return logger.LevelDebug
+	}
+	return logger.LevelInfo
+}
+
+// Enabled tests whether this LogSink is enabled at the specified V-level
+func (s *logrSink) Enabled(level int) bool {
+	return logger.Enabled(s.logger, logrLevel(level))
+}
+
+// Info logs a non-error message with the given key/value pairs as context
+func (s *logrSink) Info(level int, msg string, kv ...interface{}) {
+	s.logger.Log(logrLevel(level), msg, logger.PairArgs(kv...))
+}
+
+// Error logs an error, with the given message and key/value pairs as context
+func (s *logrSink) Error(err error, msg string, kv ...interface{}) {
+	opts := []logger.Option{logger.PairArgs(kv...)}
+	if err != nil {
+		opts = append(opts, logger.Err(err))
+	}
+	s.logger.Error(msg, opts...)
+}
+
+// WithValues returns a new LogSink with additional key/value pairs
+func (s *logrSink) WithValues(kv ...interface{}) logr.LogSink {
+	return &logrSink{logger: s.logger.WithFields(pairs(kv))}
+}
+
+// WithName returns a new LogSink with the specified name appended
+func (s *logrSink) WithName(name string) logr.LogSink {
+	return &logrSink{logger: s.logger.Named(name)}
+}
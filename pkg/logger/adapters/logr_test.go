@@ -0,0 +1,43 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func TestLogr(t *testing.T) {
+	r := loggertest.NewRecorder(nil)
+	l := NewLogr(r.WithTags(logger.Tags{"controller"})).WithName("reconciler").WithValues("namespace", "default")
+	l.Info("reconciled", "object", "pod", "retries", 1)
+	l.V(1).Info("cache hit", 42)
+	l.Error(errors.New("conflict"), "update failed", "object", "pod")
+
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelInfo), loggertest.ByMessage("^reconciled$"),
+		loggertest.ByTag("controller"), loggertest.ByName("reconciler"),
+		loggertest.ByField("namespace", "default"), loggertest.ByField("object", "pod"), loggertest.ByField("retries", 1))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelDebug), loggertest.ByField(logger.FieldBadKey, 42))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelError), loggertest.ByMessage("^update failed$"),
+		loggertest.ByField(logger.FieldError, "conflict"), loggertest.ByField("namespace", "default"))
+}
+
+func TestLogrEnabled(t *testing.T) {
+	z := logger.WrapLogger(context.Background(), zap.NewNop(), &logger.Config{
+		Level:  logger.LevelInfo,
+		Levels: map[string]logger.Level{"reconciler": logger.LevelDebug},
+	})
+	l := NewLogr(z)
+	if !l.Enabled() || l.V(1).Enabled() {
+		t.Errorf("unexpected enabled levels: info %v, debug %v", l.Enabled(), l.V(1).Enabled())
+	}
+	if !l.WithName("reconciler").V(1).Enabled() {
+		t.Error("debug level of named logger isn't enabled")
+	}
+	if !NewLogr(loggertest.NewRecorder(nil)).V(1).Enabled() {
+		t.Error("logger without level filter doesn't enable debug level")
+	}
+}
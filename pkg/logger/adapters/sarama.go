@@ -0,0 +1,31 @@
+package adapters
+
+import (
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+)
+
+// Sarama is adapter to sarama.StdLogger, e.g. sarama.Logger = adapters.NewSarama(log, logger.LevelDebug)
+type Sarama struct {
+	logger logger.Logger
+	level  logger.Level
+}
+
+// NewSarama returns adapter writes entries into l with level
+func NewSarama(l logger.Logger, level logger.Level) *Sarama {
+	return &Sarama{logger: l, level: level}
+}
+
+// Print is like fmt.Print
+func (s *Sarama) Print(v ...interface{}) {
+	s.logger.Log(s.level, sprint(false, v))
+}
+
+// Printf is like fmt.Printf
+func (s *Sarama) Printf(format string, v ...interface{}) {
+	s.logger.Log(s.level, format, logger.Args(v...))
+}
+
+// Println is like fmt.Println
+func (s *Sarama) Println(v ...interface{}) {
+	s.logger.Log(s.level, sprint(true, v))
+}
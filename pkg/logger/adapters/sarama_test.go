@@ -0,0 +1,26 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+)
+
+// stdLogger is the same as sarama.StdLogger
+type stdLogger interface {
+	Print(v ...interface{})
+	Printf(format string, v ...interface{})
+	Println(v ...interface{})
+}
+
+func TestSarama(t *testing.T) {
+	r := loggertest.NewRecorder(nil)
+	var l stdLogger = NewSarama(r.WithFields(logger.Fields{"client": "kafka"}), logger.LevelDebug)
+	l.Print("connected to ", "broker", 1)
+	l.Printf("partition %d", 3)
+	l.Println("closed", "broker", 1)
+	for _, msg := range []string{`^connected to broker1$`, `^partition 3$`, `^closed broker 1$`} {
+		loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelDebug), loggertest.ByMessage(msg), loggertest.ByField("client", "kafka"))
+	}
+}
@@ -0,0 +1,95 @@
+//go:build go1.21
+// +build go1.21
+
+package adapters
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+)
+
+// slogHandler is slog.Handler writes records into logger
+type slogHandler struct {
+	logger logger.Logger
+	group  string
+}
+
+// NewSlogHandler returns slog.Handler writes records into l, e.g. slog.New(adapters.NewSlogHandler(log))
+func NewSlogHandler(l logger.Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+// slogLevel returns RFC5424 level of slog level
+func slogLevel(l slog.Level) logger.Level {
+	switch {
+	case l < slog.LevelInfo:
+		return logger.LevelDebug
+	case l < slog.LevelWarn:
+		return logger.LevelInfo
+	case l < slog.LevelError:
+		return logger.LevelWarning
+	}
+	return logger.LevelError
+}
+
+// Enabled reports whether the handler handles records at the given level
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return logger.Enabled(h.logger, slogLevel(level))
+}
+
+// Handle handles the Record
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(logger.Fields, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(fields, h.group, a)
+		return true
+	})
+	h.logger.Log(slogLevel(r.Level), r.Message, logger.WithFields(fields), logger.WithContext(ctx))
+	return nil
+}
+
+// WithAttrs returns a new Handler whose attributes consist of both the receiver's attributes and the arguments
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make(logger.Fields, len(attrs))
+	for _, a := range attrs {
+		addAttr(fields, h.group, a)
+	}
+	return &slogHandler{logger: h.logger.WithFields(fields), group: h.group}
+}
+
+// WithGroup returns a new Handler with the given group appended to the receiver's existing groups
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{logger: h.logger, group: groupKey(h.group, name)}
+}
+
+// addAttr adds attribute into fields, keys of groups are joined by dot
+func addAttr(fields logger.Fields, group string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		// inline group without key
+		prefix := group
+		if a.Key != "" {
+			prefix = groupKey(group, a.Key)
+		}
+		for _, ga := range v.Group() {
+			addAttr(fields, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	fields[groupKey(group, a.Key)] = v.Any()
+}
+
+func groupKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	return group + "." + key
+}
@@ -0,0 +1,41 @@
+//go:build go1.21
+// +build go1.21
+
+package adapters
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+	"go.uber.org/zap"
+)
+
+func TestSlogHandler(t *testing.T) {
+	r := loggertest.NewRecorder(nil)
+	l := slog.New(NewSlogHandler(r.WithTags(logger.Tags{"api"}))).With("service", "billing").WithGroup("req")
+	l.Info("handled", "status", 200, slog.Group("user", "id", 7))
+	l.Debug("details")
+	l.Warn("slow")
+	l.Error("failed", "err", "timeout")
+
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelInfo), loggertest.ByMessage("^handled$"), loggertest.ByTag("api"),
+		loggertest.ByField("service", "billing"), loggertest.ByField("req.status", 200), loggertest.ByField("req.user.id", 7))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelDebug), loggertest.ByMessage("^details$"))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelWarning), loggertest.ByMessage("^slow$"))
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelError), loggertest.ByField("req.err", "timeout"))
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	z := logger.WrapLogger(context.Background(), zap.NewNop(), &logger.Config{Level: logger.LevelWarning})
+	h := NewSlogHandler(z)
+	ctx := context.Background()
+	if !h.Enabled(ctx, slog.LevelWarn) || !h.Enabled(ctx, slog.LevelError) {
+		t.Error("levels at or above warning aren't enabled")
+	}
+	if h.Enabled(ctx, slog.LevelInfo) || h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("levels below warning are enabled")
+	}
+}
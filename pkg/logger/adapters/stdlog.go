@@ -0,0 +1,12 @@
+package adapters
+
+import (
+	"log"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+)
+
+// NewStdLogger returns *log.Logger writes entries into l with level
+func NewStdLogger(l logger.Logger, level logger.Level) *log.Logger {
+	return log.New(logger.NewLevelWriter(l, level), "", 0)
+}
@@ -0,0 +1,15 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+)
+
+func TestStdLogger(t *testing.T) {
+	r := loggertest.NewRecorder(nil)
+	l := NewStdLogger(r.WithTags(logger.Tags{"http"}), logger.LevelWarning)
+	l.Printf("server error: %d%%", 50)
+	loggertest.AssertLogged(t, r, loggertest.ByLevel(logger.LevelWarning), loggertest.ByMessage(`^server error: 50%$`), loggertest.ByTag("http"))
+}
@@ -0,0 +1,280 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	AsyncBlock       = "block"
+	AsyncDropNewest  = "drop_newest"
+	AsyncDropOldest  = "drop_oldest"
+	AsyncDropLevel   = "drop_level"
+	MetricAsyncDepth = "async_depth"
+	ReasonBufferFull = "buffer_full"
+)
+
+var ErrUnknownPolicy = errors.New("unknown policy of async writing")
+
+// AsyncCfg is a setting of asynchronous writing, every sink gets own buffer
+type AsyncCfg struct {
+	Enabled bool
+	// Size is a capacity of buffer in entries
+	Size int `default:"1024"`
+	// BatchSize is a maximum count of entries written at once
+	BatchSize int `default:"128"`
+	// Policy is one of block, drop_newest, drop_oldest or drop_level, it's applied when buffer is full
+	Policy string `default:"block"`
+	// DropLevel is used by drop_level policy, less severe entries are dropped and others wait for free space
+	DropLevel Level `default:"4"`
+}
+
+type asyncEntry struct {
+	level Level
+	buf   *buffer.Buffer
+}
+
+// asyncWriter keeps encoded entries in bounded ring buffer and writes them by batches in background
+type asyncWriter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	ring     []asyncEntry
+	head     int
+	count    int
+	inflight bool
+	closed   bool
+	wake     chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+	out      zapcore.WriteSyncer
+	join     bool
+	cfg      AsyncCfg
+	metrics  *metrics
+	sink     string
+}
+
+// newAsyncWriter returns writer into out, entries of batch are joined into single write if join is true
+func newAsyncWriter(out zapcore.WriteSyncer, cfg AsyncCfg, join bool, m *metrics, sink string) (*asyncWriter, error) {
+	switch cfg.Policy {
+	case "":
+		cfg.Policy = AsyncBlock
+	case AsyncBlock, AsyncDropNewest, AsyncDropOldest, AsyncDropLevel:
+	default:
+		return nil, errors.WithMessage(ErrUnknownPolicy, cfg.Policy)
+	}
+	if cfg.Size <= 0 {
+		cfg.Size = 1
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+	w := &asyncWriter{
+		ring:    make([]asyncEntry, cfg.Size),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		out:     out,
+		join:    join,
+		cfg:     cfg,
+		metrics: m,
+		sink:    sink,
+	}
+	w.cond = sync.NewCond(&w.mu)
+	w.wg.Add(1)
+	go w.run()
+	return w, nil
+}
+
+func (w *asyncWriter) run() {
+	defer w.wg.Done()
+	for {
+		select {
+		case <-w.wake:
+		case <-w.done:
+			for w.flush() {
+			}
+			return
+		}
+		for w.flush() {
+		}
+	}
+}
+
+func (w *asyncWriter) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue puts entry into buffer applying policy if buffer is full, it takes ownership of buf
+func (w *asyncWriter) enqueue(level Level, buf *buffer.Buffer) error {
+	w.mu.Lock()
+	for w.count == len(w.ring) && !w.closed {
+		switch {
+		case w.cfg.Policy == AsyncDropNewest, w.cfg.Policy == AsyncDropLevel && level > w.cfg.DropLevel:
+			w.mu.Unlock()
+			w.drop(level, buf)
+			return nil
+		case w.cfg.Policy == AsyncDropOldest:
+			old := w.ring[w.head]
+			w.ring[w.head] = asyncEntry{}
+			w.head = (w.head + 1) % len(w.ring)
+			w.count--
+			w.drop(old.level, old.buf)
+		default:
+			w.cond.Wait()
+		}
+	}
+	if w.closed {
+		w.mu.Unlock()
+		defer buf.Free()
+		_, e := w.out.Write(buf.Bytes())
+		return e
+	}
+	w.ring[(w.head+w.count)%len(w.ring)] = asyncEntry{level: level, buf: buf}
+	w.count++
+	w.mu.Unlock()
+	w.notify()
+	return nil
+}
+
+func (w *asyncWriter) drop(level Level, buf *buffer.Buffer) {
+	buf.Free()
+	w.metrics.inc(MetricDropped, map[string]string{
+		MetricTagReason: ReasonBufferFull,
+		MetricTagLevel:  level.String(),
+		MetricTagSink:   w.sink,
+	})
+}
+
+// flush writes single batch, returns false if buffer is empty
+func (w *asyncWriter) flush() bool {
+	w.mu.Lock()
+	n := w.count
+	if n > w.cfg.BatchSize {
+		n = w.cfg.BatchSize
+	}
+	if n == 0 {
+		w.mu.Unlock()
+		return false
+	}
+	batch := make([]asyncEntry, n)
+	for i := range batch {
+		batch[i] = w.ring[w.head]
+		w.ring[w.head] = asyncEntry{}
+		w.head = (w.head + 1) % len(w.ring)
+	}
+	w.count -= n
+	depth := w.count
+	w.inflight = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+
+	w.write(batch)
+	w.metrics.load().Tagged(map[string]string{MetricTagSink: w.sink}).Gauge(MetricAsyncDepth).Update(float64(depth))
+
+	w.mu.Lock()
+	w.inflight = false
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	return true
+}
+
+func (w *asyncWriter) write(batch []asyncEntry) {
+	var failed bool
+	if w.join && len(batch) > 1 {
+		joined := batch[0].buf
+		for _, entry := range batch[1:] {
+			_, _ = joined.Write(entry.buf.Bytes())
+			entry.buf.Free()
+		}
+		_, e := w.out.Write(joined.Bytes())
+		joined.Free()
+		failed = e != nil
+	} else {
+		for _, entry := range batch {
+			if _, e := w.out.Write(entry.buf.Bytes()); e != nil {
+				failed = true
+			}
+			entry.buf.Free()
+		}
+	}
+	if failed {
+		w.metrics.inc(MetricWriteErrors, map[string]string{MetricTagSink: w.sink})
+	}
+}
+
+// Sync waits until buffered entries are written and flushes underlying output
+func (w *asyncWriter) Sync() error {
+	w.mu.Lock()
+	for w.count > 0 || w.inflight {
+		w.notify()
+		w.cond.Wait()
+	}
+	w.mu.Unlock()
+	return w.out.Sync()
+}
+
+// Close writes buffered entries and stops background writing, later entries are written synchronously
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.cond.Broadcast()
+	w.mu.Unlock()
+	close(w.done)
+	w.wg.Wait()
+	return w.out.Sync()
+}
+
+// asyncCore is zapcore.Core encodes entries in caller goroutine and writes them asynchronously
+type asyncCore struct {
+	zapcore.LevelEnabler
+	enc    zapcore.Encoder
+	writer *asyncWriter
+}
+
+// With adds structured context to the Core.
+func (c *asyncCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &asyncCore{LevelEnabler: c.LevelEnabler, enc: c.enc.Clone(), writer: c.writer}
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return clone
+}
+
+// Check determines whether the supplied Entry should be logged.
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write serializes the Entry and any Fields supplied at the log site and writes them to their destination.
+func (c *asyncCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, e := c.enc.EncodeEntry(ent, fields)
+	if e != nil {
+		return e
+	}
+	level := levelFromZap(ent.Level)
+	if e = c.writer.enqueue(level, buf); e != nil {
+		return e
+	}
+	// emergency entry is followed by panic
+	if level == LevelEmergency {
+		return c.writer.Sync()
+	}
+	return nil
+}
+
+// Sync flushes buffered logs (if any).
+func (c *asyncCore) Sync() error {
+	return c.writer.Sync()
+}
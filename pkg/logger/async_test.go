@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/uber-go/tally"
+	"go.uber.org/zap/buffer"
+)
+
+// gateWriter blocks writes until gate is closed
+type gateWriter struct {
+	mu      sync.Mutex
+	once    sync.Once
+	entered chan struct{}
+	gate    chan struct{}
+	lines   []string
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines = append(w.lines, string(p))
+	return len(p), nil
+}
+
+func (w *gateWriter) Sync() error {
+	return nil
+}
+
+func TestAsyncPolicies(t *testing.T) {
+	pool := buffer.NewPool()
+	entry := func(s string) *buffer.Buffer {
+		buf := pool.Get()
+		buf.AppendString(s)
+		return buf
+	}
+	for _, tc := range []struct {
+		policy   string
+		blocking int
+		expected []string
+		dropped  int64
+	}{
+		{policy: AsyncBlock, blocking: 2, expected: []string{"0", "1", "2", "3", "4"}},
+		{policy: AsyncDropNewest, expected: []string{"0", "1", "2"}, dropped: 2},
+		{policy: AsyncDropOldest, expected: []string{"0", "3", "4"}, dropped: 2},
+		{policy: AsyncDropLevel, blocking: 1, expected: []string{"0", "1", "2", "4"}, dropped: 1},
+	} {
+		t.Run(tc.policy, func(t *testing.T) {
+			scope := tally.NewTestScope("", nil)
+			m := newMetrics()
+			m.set(scope)
+			out := &gateWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+			w, err := newAsyncWriter(out, AsyncCfg{Size: 2, BatchSize: 1, Policy: tc.policy, DropLevel: LevelWarning}, false, m, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the first entry holds background writing, the next two fill the buffer
+			_ = w.enqueue(LevelInfo, entry("0"))
+			<-out.entered
+			_ = w.enqueue(LevelInfo, entry("1"))
+			_ = w.enqueue(LevelInfo, entry("2"))
+			rest := []struct {
+				level Level
+				text  string
+			}{{LevelDebug, "3"}, {LevelError, "4"}}
+			split := len(rest) - tc.blocking
+			for _, r := range rest[:split] {
+				_ = w.enqueue(r.level, entry(r.text))
+			}
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, r := range rest[split:] {
+					_ = w.enqueue(r.level, entry(r.text))
+				}
+			}()
+			close(out.gate)
+			wg.Wait()
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(out.lines, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, out.lines)
+			}
+			var dropped int64
+			for _, c := range scope.Snapshot().Counters() {
+				if c.Name() == MetricDropped {
+					dropped += c.Value()
+				}
+			}
+			if dropped != tc.dropped {
+				t.Errorf("expected %d dropped entries, got %d", tc.dropped, dropped)
+			}
+		})
+	}
+}
+
+func TestAsyncSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	path := filepath.Join(dir, "async.log")
+	log := NewZap(ctx, &Config{
+		Level:                 LevelInfo,
+		DisableRedirectStdLog: true,
+		Sampling:              SamplingCfg{Disabled: true},
+		Sinks:                 []SinkCfg{{Type: SinkFile, Path: path}},
+		Async:                 AsyncCfg{Enabled: true, Size: 16, BatchSize: 4, Policy: AsyncBlock},
+		invoker:               invoker.NewInvoker(),
+	})
+	for i := 0; i < 100; i++ {
+		log.Info("entry %d", Args(i))
+	}
+	if err := log.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(b)), "\n"); len(lines) != 100 || !strings.Contains(lines[99], "entry 99") {
+		t.Errorf("unexpected output: %d lines", len(lines))
+	}
+}
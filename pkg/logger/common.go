@@ -4,15 +4,20 @@ import (
 	"context"
 	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
 	"github.com/mitchellh/mapstructure"
+	"go.uber.org/zap/zapcore"
 	"reflect"
+	"sync"
+	"sync/atomic"
 )
 
 const (
+	Prefix                = "go-core.logger"
 	UnmarshalKey          = "logger"
 	UnmarshalKeyDebug     = UnmarshalKey + ".debug"
 	UnmarshalKeyVerbose   = UnmarshalKey + ".verbose"
 	UnmarshalKeyDebugTags = UnmarshalKey + ".debugTags"
 	UnmarshalKeyLevel     = UnmarshalKey + ".level"
+	UnmarshalKeySpanLogs  = UnmarshalKey + ".spanLogs"
 )
 
 type (
@@ -90,12 +95,38 @@ const (
 
 type opts struct {
 	wargs             []interface{}
+	zfields           []zapcore.Field
 	args              []interface{}
 	tags              Tags
 	fields            Fields
 	pfields           Fields
 	ignoreLevelFilter bool
+	unlimited         bool
 	stack             *string
+	ctx               context.Context
+	// buf is reused for building fields of entry
+	buf   []zapcore.Field
+	entry filterEntry
+}
+
+var optsPool = sync.Pool{New: func() interface{} { return &opts{} }}
+
+// acquireOpts returns options from pool, slices of fields are reused between calls
+func acquireOpts() *opts {
+	return optsPool.Get().(*opts)
+}
+
+func releaseOpts(o *opts) {
+	zfields, buf := clearFields(o.zfields), clearFields(o.buf)
+	*o = opts{zfields: zfields, buf: buf}
+	optsPool.Put(o)
+}
+
+func clearFields(fields []zapcore.Field) []zapcore.Field {
+	for i := range fields {
+		fields[i] = zapcore.Field{}
+	}
+	return fields[:0]
 }
 
 // Option is func hook for underling logic call
@@ -152,6 +183,14 @@ func WithFields(fields Fields) Option {
 	}
 }
 
+// WithContext returns func hook a logger for passing context of call, e.g. for writing log into active span
+func WithContext(ctx context.Context) Option {
+	return func(f *opts) error {
+		f.ctx = ctx
+		return nil
+	}
+}
+
 // IgnoreLevelFilter returns func hook a logger for ignore filter by logger level
 func IgnoreLevelFilter() Option {
 	return func(f *opts) error {
@@ -165,11 +204,21 @@ type Config struct {
 	Debug                 bool `fallback:"shared.debug"`
 	Verbose               bool
 	Level                 Level
+	Levels                map[string]Level
 	DebugTags             []string
+	Filter                string
 	MapTagsSplitSep       string `default:":"`
 	DisableRedirectStdLog bool
 	RedirectLevel         Level `default:"6"`
+	SpanLogs              bool
+	Sinks                 []SinkCfg
+	Sampling              SamplingCfg
+	RateLimit             RateLimitCfg
+	Async                 AsyncCfg
+	Mask                  MaskCfg
 	invoker               *invoker.Invoker
+	// snapshot is a copy of config taken on reload, subscribers read it while config is decoded by next reload
+	snapshot atomic.Value
 }
 
 // OnReload
@@ -179,9 +228,19 @@ func (c *Config) OnReload(callback func(ctx context.Context)) {
 
 // Reload
 func (c *Config) Reload(ctx context.Context) {
+	snapshot := cloneConfig(c)
+	c.snapshot.Store(&snapshot)
 	c.invoker.Reload(ctx)
 }
 
+// loaded returns copy of config taken on the last reload, config itself is returned before reload
+func (c *Config) loaded() *Config {
+	if s, ok := c.snapshot.Load().(*Config); ok {
+		return s
+	}
+	return c
+}
+
 // Logger is the interface for logger client
 type Logger interface {
 	// Printf is like fmt.Printf, push to log entry with debug level
@@ -212,6 +271,28 @@ type Logger interface {
 	WithFields(fields Fields) Logger
 	// WithTags create new instance with tags
 	WithTags(tags Tags) Logger
+	// Named create new instance with name joined to the current one, e.g. billing.stripe
+	Named(name string) Logger
+}
+
+// LevelEnabler is implemented by loggers drop entries by level before formatting
+type LevelEnabler interface {
+	// Enabled returns false if entries of level are dropped
+	Enabled(level Level) bool
+}
+
+// Enabled returns false if entries of level are dropped by l, loggers without level filter pass all levels
+func Enabled(l Logger, level Level) bool {
+	if le, ok := l.(LevelEnabler); ok {
+		return le.Enabled(level)
+	}
+	return true
+}
+
+// Syncer is implemented by loggers which buffer entries
+type Syncer interface {
+	// Sync flushes buffered entries
+	Sync() error
 }
 
 // StringToLoggerLevelHookFunc returns decoder func hook for converting string representation to RFC5424 level
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FieldError      = "error"
+	FieldErrorType  = "error_type"
+	FieldErrorChain = "error_chain"
+	FieldErrorStack = "error_stack"
+	FieldErrorKey   = "error_key"
+	// maxErrorChain limits walking through chain of errors, e.g. when error wraps itself
+	maxErrorChain = 32
+)
+
+type (
+	causer interface {
+		Cause() error
+	}
+	wrapper interface {
+		Unwrap() error
+	}
+	stackTracer interface {
+		StackTrace() errors.StackTrace
+	}
+)
+
+// Err returns func hook a logger for adding fields of error: message, type, chain of causes,
+// stack trace captured by github.com/pkg/errors and deduplication key, nil error adds nothing
+func Err(err error) Option {
+	return func(f *opts) error {
+		if err == nil {
+			return nil
+		}
+		chain := newErrorChain(err)
+		f.zfields = append(f.zfields,
+			zap.String(FieldError, err.Error()),
+			zap.String(FieldErrorType, errorType(err)),
+		)
+		if len(chain) > 1 {
+			f.zfields = append(f.zfields, zap.Array(FieldErrorChain, chain[1:]))
+		}
+		if st := chain.stackTrace(); st != nil {
+			f.zfields = append(f.zfields, zap.Stringer(FieldErrorStack, stackString{st}))
+		}
+		f.zfields = append(f.zfields, zap.Stringer(FieldErrorKey, chain))
+		return nil
+	}
+}
+
+// errorChain is a sequence of error and its causes, pkg/errors Cause is preferred to Go 1.13 Unwrap
+type errorChain []error
+
+func newErrorChain(err error) errorChain {
+	chain := errorChain{err}
+	for len(chain) < maxErrorChain {
+		var next error
+		switch e := err.(type) {
+		case causer:
+			next = e.Cause()
+		case wrapper:
+			next = e.Unwrap()
+		}
+		if next == nil || sameError(next, err) {
+			break
+		}
+		chain = append(chain, next)
+		err = next
+	}
+	return chain
+}
+
+// sameError returns true if errors are equal, comparing of errors of non-comparable type panics
+func sameError(a, b error) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// MarshalLogArray writes message and type of every error in chain
+func (c errorChain) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range c {
+		e := err
+		_ = enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("message", e.Error())
+			enc.AddString("type", errorType(e))
+			return nil
+		}))
+	}
+	return nil
+}
+
+// stackTrace returns the deepest stack trace in chain, it's the closest to origin of error
+func (c errorChain) stackTrace() errors.StackTrace {
+	for i := len(c) - 1; i >= 0; i-- {
+		if st, ok := c[i].(stackTracer); ok {
+			return st.StackTrace()
+		}
+	}
+	return nil
+}
+
+// String returns deduplication key, errors of the same root type raised at the same line have the same key.
+// Message of root cause is used instead of line if stack trace is not captured.
+func (c errorChain) String() string {
+	root := c[len(c)-1]
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(errorType(root)))
+	if st := c.stackTrace(); len(st) > 0 {
+		_, _ = fmt.Fprintf(h, "\n%+s:%d", st[0], st[0])
+	} else {
+		_, _ = h.Write([]byte(root.Error()))
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func errorType(err error) string {
+	return fmt.Sprintf("%T", err)
+}
+
+type stackString struct {
+	st errors.StackTrace
+}
+
+// String
+func (s stackString) String() string {
+	return fmt.Sprintf("%+v", s.st)
+}
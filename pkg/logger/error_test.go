@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func errorFields(err error) map[string]interface{} {
+	o := &opts{}
+	_ = Err(err)(o)
+	return fieldsMap(o.zfields)
+}
+
+func TestErr(t *testing.T) {
+	newErr := func() error {
+		return errors.Wrap(errors.WithMessage(io.EOF, "read body"), "request")
+	}
+	fields := errorFields(newErr())
+	if fields[FieldError] != "request: read body: EOF" || fields[FieldErrorType] != "*errors.withStack" {
+		t.Errorf("unexpected message or type: %v", fields)
+	}
+	chain, _ := fields[FieldErrorChain].([]interface{})
+	if len(chain) != 3 {
+		t.Fatalf("unexpected chain: %v", fields[FieldErrorChain])
+	}
+	if root := chain[2].(map[string]interface{}); root["message"] != "EOF" || root["type"] != "*errors.errorString" {
+		t.Errorf("unexpected root cause: %v", root)
+	}
+	if stack, _ := fields[FieldErrorStack].(string); !strings.Contains(stack, "TestErr") {
+		t.Errorf("stack trace is not recorded: %q", stack)
+	}
+	if key := errorFields(newErr())[FieldErrorKey]; key != fields[FieldErrorKey] {
+		t.Errorf("errors raised at the same place have different keys: %v, %v", key, fields[FieldErrorKey])
+	}
+	if key := errorFields(errors.Wrap(io.EOF, "request"))[FieldErrorKey]; key == fields[FieldErrorKey] {
+		t.Error("errors raised at different places have the same key")
+	}
+	if len(errorFields(nil)) != 0 {
+		t.Error("nil error adds fields")
+	}
+}
+
+// listError is non-comparable error wraps the first of errors
+type listError []error
+
+func (e listError) Error() string {
+	return "list"
+}
+
+func (e listError) Cause() error {
+	return e[0]
+}
+
+func TestErrNonComparable(t *testing.T) {
+	chain, _ := errorFields(listError{listError{io.EOF}})[FieldErrorChain].([]interface{})
+	if len(chain) != 2 {
+		t.Fatalf("unexpected chain: %v", chain)
+	}
+	if root := chain[1].(map[string]interface{}); root["message"] != "EOF" {
+		t.Errorf("unexpected root cause: %v", root)
+	}
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const FieldBadKey = "!BADKEY"
+
+// field returns func hook a logger for adding typed zap field
+func field(zf zapcore.Field) Option {
+	return func(f *opts) error {
+		f.zfields = append(f.zfields, zf)
+		return nil
+	}
+}
+
+// String returns func hook a logger for adding string field
+func String(key, val string) Option {
+	return field(zap.String(key, val))
+}
+
+// Strings returns func hook a logger for adding field of strings
+func Strings(key string, val []string) Option {
+	return field(zap.Strings(key, val))
+}
+
+// Int returns func hook a logger for adding int field
+func Int(key string, val int) Option {
+	return field(zap.Int(key, val))
+}
+
+// Int64 returns func hook a logger for adding int64 field
+func Int64(key string, val int64) Option {
+	return field(zap.Int64(key, val))
+}
+
+// Float64 returns func hook a logger for adding float64 field
+func Float64(key string, val float64) Option {
+	return field(zap.Float64(key, val))
+}
+
+// Bool returns func hook a logger for adding bool field
+func Bool(key string, val bool) Option {
+	return field(zap.Bool(key, val))
+}
+
+// Duration returns func hook a logger for adding duration field
+func Duration(key string, val time.Duration) Option {
+	return field(zap.Duration(key, val))
+}
+
+// Time returns func hook a logger for adding time field
+func Time(key string, val time.Time) Option {
+	return field(zap.Time(key, val))
+}
+
+// Object returns func hook a logger for adding field of value marshaled by itself without reflection
+func Object(key string, val zapcore.ObjectMarshaler) Option {
+	return field(zap.Object(key, val))
+}
+
+// mapFields appends fields of map sorted by key for stable order
+func mapFields(dst []zapcore.Field, m map[string]interface{}) []zapcore.Field {
+	switch len(m) {
+	case 0:
+		return dst
+	case 1:
+		for k, v := range m {
+			dst = append(dst, zap.Any(k, v))
+		}
+		return dst
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		dst = append(dst, zap.Any(k, m[k]))
+	}
+	return dst
+}
+
+// pairFields appends fields of key/value pairs, zap fields are passed as is,
+// values without string key are added under FieldBadKey
+func pairFields(dst []zapcore.Field, kv []interface{}) []zapcore.Field {
+	for i := 0; i < len(kv); {
+		if zf, ok := kv[i].(zapcore.Field); ok {
+			dst = append(dst, zf)
+			i++
+			continue
+		}
+		key, ok := kv[i].(string)
+		if !ok || i+1 == len(kv) {
+			dst = append(dst, zap.Any(FieldBadKey, kv[i]))
+			i++
+			continue
+		}
+		dst = append(dst, zap.Any(key, kv[i+1]))
+		i += 2
+	}
+	return dst
+}
+
+// fieldsMap returns values of zap fields as they are seen by encoders
+func fieldsMap(fields []zapcore.Field) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return enc.Fields
+}
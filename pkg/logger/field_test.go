@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newFieldsLogger(w zapcore.WriteSyncer) *Zap {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg", EncodeDuration: zapcore.StringDurationEncoder})
+	core := zapcore.NewCore(enc, w, zapLevel(LevelDebug))
+	return WrapLogger(context.Background(), zap.New(core), &Config{Level: LevelDebug})
+}
+
+func TestTypedFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := newFieldsLogger(zapcore.AddSync(&buf))
+	log.WithFields(Fields{"b": 2, "a": 1}).Info("paid",
+		String("user", "john"),
+		Int("amount", 42),
+		Duration("took", time.Second),
+		Bool("declined", true),
+		PairArgs("odd"),
+	)
+	expected := `{"msg":"paid","a":1,"b":2,"!BADKEY":"odd","user":"john","amount":42,"took":"1s","declined":true}`
+	if got := strings.TrimSpace(buf.String()); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func BenchmarkFieldsMap(b *testing.B) {
+	log := newFieldsLogger(zapcore.AddSync(ioutil.Discard))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("paid", WithFields(Fields{"user": "john", "amount": 42, "took": time.Second}))
+	}
+}
+
+func BenchmarkTypedFields(b *testing.B) {
+	log := newFieldsLogger(zapcore.AddSync(ioutil.Discard))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		log.Info("paid", String("user", "john"), Int("amount", 42), Duration("took", time.Second))
+	}
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const fieldTags = "tags"
+
+// newFilter returns filter matched by one of DebugTags and by filter expression
+func newFilter(debugTags []string, sep, expr string) (*filterExpr, error) {
+	compiled, e := compileFilter(expr)
+	if e != nil {
+		return nil, e
+	}
+	return newTagFilter(debugTags, sep).and(compiled), nil
+}
+
+// newTagFilter returns filter matched by one of plain tags or key:value pairs from DebugTags setting
+func newTagFilter(debugTags []string, sep string) *filterExpr {
+	var root filterNode
+	or := func(node filterNode) {
+		if root == nil {
+			root = node
+		} else {
+			root = &orNode{left: root, right: node}
+		}
+	}
+	for _, tag := range debugTags {
+		or(&tagNode{pattern: tag})
+	}
+	for i, pairs := 0, parseTagsMap(debugTags, sep); i+1 < len(pairs); i += 2 {
+		num, isNum := parseFilterNumber(pairs[i+1])
+		or(&condNode{key: pairs[i], op: "=", value: pairs[i+1], num: num, isNum: isNum})
+	}
+	return &filterExpr{root: root}
+}
+
+func parseTagsMap(debugTags []string, sep string) []string {
+	var tagsMap []string
+	for _, requiredTag := range debugTags {
+		rt := strings.Split(requiredTag, sep)
+		if len(rt) == 2 {
+			tagsMap = append(tagsMap, rt...)
+		}
+	}
+	return tagsMap
+}
+
+// fieldsTags extracts tags from zap fields
+func fieldsTags(fields ...[]zapcore.Field) []string {
+	var tags []string
+	for _, ff := range fields {
+		for _, field := range ff {
+			if field.Key == fieldTags && field.Type == zapcore.ArrayMarshalerType {
+				if arr, ok := fieldsMap([]zapcore.Field{field})[fieldTags].([]interface{}); ok {
+					for _, v := range arr {
+						if s, ok := v.(string); ok {
+							tags = append(tags, s)
+						}
+					}
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// filterCore is zapcore.Core drops entries which is not matched by the filter of sink
+type filterCore struct {
+	zapcore.Core
+	filter *filterExpr
+	fields []zapcore.Field
+}
+
+// With adds structured context to the Core.
+func (c *filterCore) With(fields []zapcore.Field) zapcore.Core {
+	ctxFields := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	ctxFields = append(ctxFields, c.fields...)
+	ctxFields = append(ctxFields, fields...)
+	return &filterCore{
+		Core:   c.Core.With(fields),
+		filter: c.filter,
+		fields: ctxFields,
+	}
+}
+
+// Check determines whether the supplied Entry should be logged.
+func (c *filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write serializes the Entry and any Fields supplied at the log site and writes them to their destination.
+func (c *filterCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	entry := &filterEntry{
+		level:     levelFromZap(ent.Level),
+		message:   ent.Message,
+		formatted: true,
+		tags:      fieldsTags(c.fields, fields),
+		ctxFields: c.fields,
+		fields:    fields,
+	}
+	if !c.filter.match(entry) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
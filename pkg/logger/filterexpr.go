@@ -0,0 +1,474 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/zapcore"
+)
+
+// Filter expression is a boolean expression of conditions, e.g.
+//
+//	tag:payments AND (user_id=42 OR level>=warning) AND NOT msg="health*"
+//
+// Conditions are:
+//   - tag:glob matches one of tags
+//   - level op name compares severity, e.g. level>=warning matches warning and more severe levels
+//   - msg op glob compares message
+//   - field op value compares field, values are compared as numbers or durations if both sides are numeric
+// Operators are =, !=, <, <=, >, >=, equality operators support globs with * and ?.
+// Keywords AND, OR, NOT are case insensitive, AND has precedence over OR. Conditions of missing fields are false.
+
+const (
+	FilterKeyTag     = "tag"
+	FilterKeyLevel   = "level"
+	FilterKeyMessage = "msg"
+)
+
+var ErrFilterSyntax = errors.New("filter syntax error")
+
+// filterEntry is a log entry evaluated by filter, message is formatted on demand
+type filterEntry struct {
+	level     Level
+	format    string
+	args      []interface{}
+	masker    *masker
+	message   string
+	formatted bool
+	tags      []string
+	// ctxFields are fields of core context, they are looked up before fields of call
+	ctxFields []zapcore.Field
+	fields    []zapcore.Field
+}
+
+func (e *filterEntry) msg() string {
+	if !e.formatted {
+		e.formatted = true
+		e.message = e.format
+		if len(e.args) > 0 {
+			args := e.args
+			if e.masker != nil {
+				args = e.masker.args(args)
+			}
+			e.message = fmt.Sprintf(e.format, args...)
+		}
+	}
+	return e.message
+}
+
+func (e *filterEntry) field(key string) (zapcore.Field, bool) {
+	for _, ff := range [2][]zapcore.Field{e.ctxFields, e.fields} {
+		for _, f := range ff {
+			if f.Key == key && f.Key != fieldTags {
+				return f, true
+			}
+		}
+	}
+	return zapcore.Field{}, false
+}
+
+type filterNode interface {
+	match(e *filterEntry) bool
+}
+
+type (
+	andNode struct{ left, right filterNode }
+	orNode  struct{ left, right filterNode }
+	notNode struct{ node filterNode }
+	tagNode struct{ pattern string }
+	// condNode compares field, level or message with value
+	condNode struct {
+		key   string
+		op    string
+		value string
+		num   float64
+		isNum bool
+		glob  bool
+	}
+)
+
+func (n *andNode) match(e *filterEntry) bool {
+	return n.left.match(e) && n.right.match(e)
+}
+
+func (n *orNode) match(e *filterEntry) bool {
+	return n.left.match(e) || n.right.match(e)
+}
+
+func (n *notNode) match(e *filterEntry) bool {
+	return !n.node.match(e)
+}
+
+func (n *tagNode) match(e *filterEntry) bool {
+	for _, tag := range e.tags {
+		if globMatch(n.pattern, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *condNode) match(e *filterEntry) bool {
+	switch n.key {
+	case FilterKeyLevel:
+		// severity grows to emergency
+		return compareNumbers(n.op, -float64(e.level), -n.num)
+	case FilterKeyMessage:
+		return n.compareText(e.msg())
+	}
+	f, ok := e.field(n.key)
+	if !ok {
+		return false
+	}
+	if n.isNum {
+		if num, ok := fieldNumber(f); ok {
+			return compareNumbers(n.op, num, n.num)
+		}
+	}
+	return n.compareText(fieldText(f))
+}
+
+func (n *condNode) compareText(s string) bool {
+	switch n.op {
+	case "=":
+		if n.glob {
+			return globMatch(n.value, s)
+		}
+		return s == n.value
+	case "!=":
+		if n.glob {
+			return !globMatch(n.value, s)
+		}
+		return s != n.value
+	case "<":
+		return s < n.value
+	case "<=":
+		return s <= n.value
+	case ">":
+		return s > n.value
+	case ">=":
+		return s >= n.value
+	}
+	return false
+}
+
+func compareNumbers(op string, a, b float64) bool {
+	switch op {
+	case "=":
+		return a == b
+	case "!=":
+		return a != b
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
+
+func fieldNumber(f zapcore.Field) (float64, bool) {
+	switch f.Type {
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type, zapcore.DurationType:
+		return float64(f.Integer), true
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return float64(uint64(f.Integer)), true
+	case zapcore.Float64Type:
+		return math.Float64frombits(uint64(f.Integer)), true
+	case zapcore.Float32Type:
+		return float64(math.Float32frombits(uint32(f.Integer))), true
+	case zapcore.StringType:
+		return parseFilterNumber(f.String)
+	}
+	return 0, false
+}
+
+func fieldText(f zapcore.Field) string {
+	switch f.Type {
+	case zapcore.StringType:
+		return f.String
+	case zapcore.BoolType:
+		return strconv.FormatBool(f.Integer == 1)
+	}
+	if num, ok := fieldNumber(f); ok && f.Type != zapcore.DurationType {
+		return strconv.FormatFloat(num, 'f', -1, 64)
+	}
+	return fieldString(fieldsMap([]zapcore.Field{f})[f.Key])
+}
+
+// parseFilterNumber parses number or duration in nanoseconds
+func parseFilterNumber(s string) (float64, bool) {
+	if num, e := strconv.ParseFloat(s, 64); e == nil {
+		return num, true
+	}
+	if d, e := time.ParseDuration(s); e == nil {
+		return float64(d), true
+	}
+	return 0, false
+}
+
+// globMatch matches string with pattern, * matches any sequence and ? matches any single character
+func globMatch(pattern, s string) bool {
+	var px, sx, nextPx, nextSx int
+	for px < len(pattern) || sx < len(s) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				nextPx, nextSx = px, sx+1
+				px++
+				continue
+			case '?':
+				if sx < len(s) {
+					px++
+					sx++
+					continue
+				}
+			default:
+				if sx < len(s) && s[sx] == c {
+					px++
+					sx++
+					continue
+				}
+			}
+		}
+		if 0 < nextSx && nextSx <= len(s) {
+			px, sx = nextPx, nextSx
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// filterExpr is compiled filter expression, nil or empty expression matches everything
+type filterExpr struct {
+	root filterNode
+}
+
+func (f *filterExpr) match(e *filterEntry) bool {
+	return f == nil || f.root == nil || f.root.match(e)
+}
+
+// and returns expression matched by both expressions
+func (f *filterExpr) and(other *filterExpr) *filterExpr {
+	switch {
+	case f == nil || f.root == nil:
+		return other
+	case other == nil || other.root == nil:
+		return f
+	}
+	return &filterExpr{root: &andNode{left: f.root, right: other.root}}
+}
+
+// compileFilter parses filter expression
+func compileFilter(expr string) (*filterExpr, error) {
+	p := &filterParser{}
+	if e := p.tokenize(expr); e != nil {
+		return nil, e
+	}
+	if len(p.tokens) == 0 {
+		return &filterExpr{}, nil
+	}
+	root, e := p.parseOr()
+	if e != nil {
+		return nil, e
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return &filterExpr{root: root}, nil
+}
+
+type filterToken struct {
+	text   string
+	quoted bool
+}
+
+type filterParser struct {
+	expr   string
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return errors.WithMessage(ErrFilterSyntax, fmt.Sprintf("%s in %q", fmt.Sprintf(format, args...), p.expr))
+}
+
+func isFilterOp(c byte) bool {
+	return c == '=' || c == '!' || c == '<' || c == '>' || c == ':'
+}
+
+func (p *filterParser) tokenize(expr string) error {
+	p.expr = expr
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')':
+			p.tokens = append(p.tokens, filterToken{text: string(c)})
+			i++
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(expr) && expr[j] != '"'; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) {
+					j++
+				}
+				b.WriteByte(expr[j])
+			}
+			if j == len(expr) {
+				return p.errorf("unterminated string")
+			}
+			p.tokens = append(p.tokens, filterToken{text: b.String(), quoted: true})
+			i = j + 1
+		case isFilterOp(c):
+			j := i + 1
+			if j < len(expr) && expr[j] == '=' && c != ':' {
+				j++
+			}
+			p.tokens = append(p.tokens, filterToken{text: expr[i:j]})
+			i = j
+		default:
+			j := i
+			for j < len(expr) && !unicode.IsSpace(rune(expr[j])) && !isFilterOp(expr[j]) &&
+				expr[j] != '(' && expr[j] != ')' && expr[j] != '"' {
+				j++
+			}
+			p.tokens = append(p.tokens, filterToken{text: expr[i:j]})
+			i = j
+		}
+	}
+	return nil
+}
+
+func (p *filterParser) peek() (filterToken, bool) {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos], true
+	}
+	return filterToken{}, false
+}
+
+// keyword returns true and moves to next token if current token is the keyword
+func (p *filterParser) keyword(kw string) bool {
+	if t, ok := p.peek(); ok && !t.quoted && strings.EqualFold(t.text, kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, e := p.parseAnd()
+	if e != nil {
+		return nil, e
+	}
+	for p.keyword("OR") {
+		right, e := p.parseAnd()
+		if e != nil {
+			return nil, e
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, e := p.parseNot()
+	if e != nil {
+		return nil, e
+	}
+	for p.keyword("AND") {
+		right, e := p.parseNot()
+		if e != nil {
+			return nil, e
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (filterNode, error) {
+	if p.keyword("NOT") {
+		node, e := p.parseNot()
+		if e != nil {
+			return nil, e
+		}
+		return &notNode{node: node}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (filterNode, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, p.errorf("unexpected end")
+	}
+	if !t.quoted && t.text == "(" {
+		p.pos++
+		node, e := p.parseOr()
+		if e != nil {
+			return nil, e
+		}
+		if t, ok := p.peek(); !ok || t.quoted || t.text != ")" {
+			return nil, p.errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	}
+	if len(p.tokens) < p.pos+3 {
+		return nil, p.errorf("incomplete condition after %q", t.text)
+	}
+	key, op, value := t, p.tokens[p.pos+1], p.tokens[p.pos+2]
+	if op.quoted || !isFilterOp(op.text[0]) || !value.quoted && (value.text == "(" || value.text == ")" || isFilterOp(value.text[0])) {
+		return nil, p.errorf("invalid condition %q", key.text+op.text+value.text)
+	}
+	p.pos += 3
+	return newCondNode(p, key.text, op.text, value.text)
+}
+
+func newCondNode(p *filterParser, key, op, value string) (filterNode, error) {
+	if op == ":" {
+		if key != FilterKeyTag {
+			return nil, p.errorf("operator : is supported by tag only")
+		}
+		return &tagNode{pattern: value}, nil
+	}
+	if op == "!" {
+		return nil, p.errorf("unknown operator !")
+	}
+	n := &condNode{key: key, op: op, value: value, glob: strings.ContainsAny(value, "*?")}
+	switch key {
+	case FilterKeyTag:
+		return nil, p.errorf("tag supports operator : only")
+	case FilterKeyLevel:
+		level, ok := parseFilterLevel(value)
+		if !ok {
+			return nil, p.errorf("unknown level %q", value)
+		}
+		n.num, n.isNum = float64(level), true
+	default:
+		n.num, n.isNum = parseFilterNumber(value)
+	}
+	return n, nil
+}
+
+func parseFilterLevel(s string) (Level, bool) {
+	if num, e := strconv.Atoi(s); e == nil && num >= int(LevelEmergency) && num <= int(LevelDebug) {
+		return Level(num), true
+	}
+	for l := LevelEmergency; l <= LevelDebug; l++ {
+		if strings.EqualFold(l.String(), s) {
+			return l, true
+		}
+	}
+	return 0, false
+}
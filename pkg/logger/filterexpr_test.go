@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFilterExpr(t *testing.T) {
+	entry := &filterEntry{
+		level:  LevelError,
+		format: "payment %s declined",
+		args:   []interface{}{"#42"},
+		tags:   []string{"payments", "stripe"},
+		fields: []zapcore.Field{
+			zap.Int("user_id", 42),
+			zap.String("amount", "10.5"),
+			zap.Duration("took", 1500*time.Millisecond),
+			zap.String("region", "eu-west-1"),
+		},
+	}
+	for expr, expected := range map[string]bool{
+		"":             true,
+		"tag:payments": true,
+		"tag:pay*":     true,
+		"tag:billing":  false,
+		"tag:payments AND (user_id=42 OR level>=warning)": true,
+		"tag:payments AND (user_id=7 OR level>=critical)": false,
+		"user_id>=40 AND user_id<50":                      true,
+		"amount>10 and took>1s":                           true,
+		"took<=1s":                                        false,
+		"region=eu-*":                                     true,
+		"region!=\"eu-*\"":                                false,
+		`msg="payment #42*"`:                              true,
+		"NOT level=error":                                 false,
+		"missing=1 OR level>notice":                       true,
+		"tag:billing OR tag:stripe AND user_id=42":        true,
+	} {
+		filter, err := compileFilter(expr)
+		if err != nil {
+			t.Errorf("%q: %v", expr, err)
+			continue
+		}
+		if got := filter.match(entry); got != expected {
+			t.Errorf("%q: expected %v, got %v", expr, expected, got)
+		}
+	}
+	for _, expr := range []string{"tag:", "user_id", "(tag:a", "tag=a", "level>=loud", "a=1 b=2", `msg="unterminated`} {
+		if _, err := compileFilter(expr); err == nil {
+			t.Errorf("%q: syntax error is expected", expr)
+		}
+	}
+}
+
+func BenchmarkFilterExpr(b *testing.B) {
+	filter, err := compileFilter("tag:payments AND (user_id=42 OR level>=warning)")
+	if err != nil {
+		b.Fatal(err)
+	}
+	fields := []zapcore.Field{zap.String("region", "eu"), zap.Int("user_id", 42)}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		filter.match(&filterEntry{level: LevelInfo, tags: []string{"payments"}, fields: fields})
+	}
+}
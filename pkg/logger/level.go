@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FieldLevel    = "level"
+	FieldSeverity = "severity"
+	// zapLevelShift shifts RFC5424 levels below standard zap levels, so they don't collide and
+	// zap doesn't apply own panic or exit behaviour to them
+	zapLevelShift = 2
+)
+
+// zapLevel returns zapcore level keeps RFC5424 severity, more severe level has greater value
+func zapLevel(l Level) zapcore.Level {
+	return zapcore.Level(-int8(l) - zapLevelShift)
+}
+
+// levelFromZap returns RFC5424 level of zapcore level, standard zap levels are mapped to the nearest severity
+func levelFromZap(l zapcore.Level) Level {
+	if l <= zapLevel(LevelEmergency) {
+		level := Level(-int8(l) - zapLevelShift)
+		if level > LevelDebug {
+			return LevelDebug
+		}
+		return level
+	}
+	switch l {
+	case zapcore.DebugLevel:
+		return LevelDebug
+	case zapcore.InfoLevel:
+		return LevelInfo
+	case zapcore.WarnLevel:
+		return LevelWarning
+	case zapcore.ErrorLevel:
+		return LevelError
+	case zapcore.DPanicLevel:
+		return LevelCritical
+	case zapcore.PanicLevel:
+		return LevelAlert
+	}
+	return LevelEmergency
+}
+
+// standardLevel returns standard zap level of zapcore level, it's inverse of levelFromZap for RFC5424 levels
+func standardLevel(l zapcore.Level) zapcore.Level {
+	if l >= zapcore.DebugLevel {
+		return l
+	}
+	switch levelFromZap(l) {
+	case LevelDebug:
+		return zapcore.DebugLevel
+	case LevelInfo, LevelNotice:
+		return zapcore.InfoLevel
+	case LevelWarning:
+		return zapcore.WarnLevel
+	case LevelError:
+		return zapcore.ErrorLevel
+	case LevelCritical:
+		return zapcore.DPanicLevel
+	case LevelAlert:
+		return zapcore.PanicLevel
+	}
+	return zapcore.FatalLevel
+}
+
+// standardCore is zapcore.Core of external logger gets standard zap levels, severity is kept by field,
+// behaviour of panic and fatal levels isn't applied because entries are checked by RFC5424 levels
+type standardCore struct {
+	zapcore.Core
+}
+
+// adaptCore returns core gets standard zap levels, core is returned as is if it enables RFC5424 levels
+func adaptCore(core zapcore.Core) zapcore.Core {
+	if core.Enabled(zapLevel(LevelDebug)) {
+		return core
+	}
+	return &standardCore{Core: core}
+}
+
+// Enabled returns true if standard level of level is enabled by underlying core
+func (c *standardCore) Enabled(l zapcore.Level) bool {
+	return c.Core.Enabled(standardLevel(l))
+}
+
+// With adds structured context to the Core.
+func (c *standardCore) With(fields []zapcore.Field) zapcore.Core {
+	return &standardCore{Core: c.Core.With(fields)}
+}
+
+// Check determines whether the supplied Entry should be logged.
+func (c *standardCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes entry with standard level and RFC5424 severity into underlying core
+func (c *standardCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(fields)+1)
+	all = append(all, fields...)
+	all = append(all, zap.Int8(FieldSeverity, int8(levelFromZap(ent.Level))))
+	ent.Level = standardLevel(ent.Level)
+	return c.Core.Write(ent, all)
+}
+
+// cfgLevelToZap returns zapcore level for filtering in cores
+func cfgLevelToZap(lvl Level) zapcore.Level {
+	return zapLevel(lvl)
+}
+
+// LevelEncoder serializes zapcore level as RFC5424 level name
+func LevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(levelFromZap(l).String())
+}
+
+// severityEncoder is zapcore.Encoder adds numeric RFC5424 severity to every entry
+type severityEncoder struct {
+	zapcore.Encoder
+}
+
+// NewSeverityEncoder returns zapcore.Encoder adds numeric RFC5424 severity to entries of underlying encoder
+func NewSeverityEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	return &severityEncoder{Encoder: enc}
+}
+
+// Clone copies the encoder, ensuring that adding fields to the copy doesn't affect the original.
+func (e *severityEncoder) Clone() zapcore.Encoder {
+	return &severityEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry encodes an entry and fields, along with any accumulated context, into a byte buffer and returns it.
+func (e *severityEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	all := make([]zapcore.Field, 0, len(fields)+1)
+	all = append(all, fields...)
+	all = append(all, zap.Int8(FieldSeverity, int8(levelFromZap(ent.Level))))
+	return e.Encoder.EncodeEntry(ent, all)
+}
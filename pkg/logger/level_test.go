@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelFidelity(t *testing.T) {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.LevelKey = FieldLevel
+	cfg.EncodeLevel = LevelEncoder
+	enc := NewSeverityEncoder(zapcore.NewJSONEncoder(cfg))
+
+	for level := LevelEmergency; level <= LevelDebug; level++ {
+		if got := levelFromZap(zapLevel(level)); got != level {
+			t.Errorf("level %s is mapped back to %s", level, got)
+		}
+		buf, e := enc.EncodeEntry(zapcore.Entry{Level: zapLevel(level), Message: "test"}, nil)
+		if e != nil {
+			t.Fatal(e)
+		}
+		var entry struct {
+			Level    string `json:"level"`
+			Severity int    `json:"severity"`
+		}
+		if e := json.Unmarshal(buf.Bytes(), &entry); e != nil {
+			t.Fatal(e)
+		}
+		if entry.Level != level.String() || entry.Severity != int(level) {
+			t.Errorf("level %s is encoded as %s with severity %d", level, entry.Level, entry.Severity)
+		}
+	}
+	if !zapLevel(LevelDebug).Enabled(zapLevel(LevelNotice)) || zapLevel(LevelNotice).Enabled(zapLevel(LevelInfo)) {
+		t.Error("levels are not ordered by severity")
+	}
+}
+
+func TestWrapLoggerStandardLevels(t *testing.T) {
+	f, e := ioutil.TempFile("", "logger")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	stderr := os.Stderr
+	os.Stderr = f
+	z, e := zap.NewProduction()
+	os.Stderr = stderr
+	if e != nil {
+		t.Fatal(e)
+	}
+	log := WrapLogger(context.Background(), z, &Config{Level: LevelDebug})
+	log.Debug("debug")
+	log.Notice("notice")
+	log.Warning("warning")
+	log.Critical("critical")
+	_ = z.Sync()
+
+	b, e := ioutil.ReadFile(f.Name())
+	if e != nil {
+		t.Fatal(e)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	expected := []struct {
+		msg, level string
+		severity   Level
+	}{
+		{"notice", "info", LevelNotice},
+		{"warning", "warn", LevelWarning},
+		{"critical", "dpanic", LevelCritical},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("unexpected entries: %s", b)
+	}
+	for i, exp := range expected {
+		var entry struct {
+			Msg      string `json:"msg"`
+			Level    string `json:"level"`
+			Severity Level  `json:"severity"`
+		}
+		if e := json.Unmarshal([]byte(lines[i]), &entry); e != nil {
+			t.Fatal(e)
+		}
+		if entry.Msg != exp.msg || entry.Level != exp.level || entry.Severity != exp.severity {
+			t.Errorf("entry %s is written as %s with severity %d", exp.msg, lines[i], entry.Severity)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MetricDropped       = "dropped"
+	MetricTagReason     = "reason"
+	MetricTagLevel      = "level"
+	ReasonSampling      = "sampling"
+	ReasonRateLimit     = "rate_limit"
+	FieldTemplate       = "template"
+	FieldSuppressed     = "suppressed"
+	suppressedTemplate  = "suppressed %d similar messages"
+	defaultSamplingTick = time.Second
+)
+
+// SamplingLevelCfg overrides sampling setting for specified level, sampling of level is disabled if Initial is zero
+type SamplingLevelCfg struct {
+	Level      Level
+	Initial    int
+	Thereafter int
+}
+
+// SamplingCfg is a setting of sampling of repeated messages, messages are counted per level and template
+// during every tick, first Initial messages are logged, after that every Thereafter message is logged.
+// Sampling is disabled in debug mode or if Initial is zero.
+type SamplingCfg struct {
+	Disabled   bool
+	Tick       time.Duration
+	Initial    int `default:"100"`
+	Thereafter int `default:"100"`
+	Levels     []SamplingLevelCfg
+}
+
+// RateLimitCfg is a setting of token bucket rate limit per message template, disabled if Rate is zero
+type RateLimitCfg struct {
+	// Rate is a count of messages per second
+	Rate float64
+	// Burst is a maximum count of messages at once, it's equal to rate if not set
+	Burst int
+}
+
+type samplingRule struct {
+	initial, thereafter uint64
+}
+
+type limiterKey struct {
+	level    Level
+	template string
+}
+
+type limiterEntry struct {
+	tick       time.Time
+	count      uint64
+	tokens     float64
+	last       time.Time
+	seen       time.Time
+	suppressed uint64
+}
+
+type suppressed struct {
+	level    Level
+	template string
+	count    uint64
+}
+
+// limiter drops repeated messages by sampling and rate limit, counts of suppressed messages are kept for summary.
+// It lives as long as logger, rules are replaced on reload.
+type limiter struct {
+	mu      sync.Mutex
+	active  int32
+	tick    time.Duration
+	rules   map[Level]*samplingRule
+	rate    float64
+	burst   float64
+	entries map[limiterKey]*limiterEntry
+}
+
+func newLimiter(cfg *Config) *limiter {
+	l := &limiter{entries: map[limiterKey]*limiterEntry{}}
+	l.update(cfg)
+	return l
+}
+
+// update replaces rules by config, counters of messages and suppressed messages are kept
+func (l *limiter) update(cfg *Config) {
+	var (
+		tick  = cfg.Sampling.Tick
+		rules = map[Level]*samplingRule{}
+		rate  = cfg.RateLimit.Rate
+		burst = float64(cfg.RateLimit.Burst)
+	)
+	if tick <= 0 {
+		tick = defaultSamplingTick
+	}
+	if burst <= 0 {
+		burst = rate
+	}
+	if !cfg.Debug && !cfg.Sampling.Disabled {
+		for level := LevelAlert; level <= LevelDebug; level++ {
+			setRule(rules, level, cfg.Sampling.Initial, cfg.Sampling.Thereafter)
+		}
+		for _, lc := range cfg.Sampling.Levels {
+			setRule(rules, lc.Level, lc.Initial, lc.Thereafter)
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tick, l.rules, l.rate, l.burst = tick, rules, rate, burst
+	var active int32
+	if len(rules) > 0 || rate > 0 {
+		active = 1
+	}
+	atomic.StoreInt32(&l.active, active)
+}
+
+func setRule(rules map[Level]*samplingRule, level Level, initial, thereafter int) {
+	if initial <= 0 {
+		delete(rules, level)
+		return
+	}
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	rules[level] = &samplingRule{initial: uint64(initial), thereafter: uint64(thereafter)}
+}
+
+// enabled returns false if limiter passes everything
+func (l *limiter) enabled() bool {
+	return atomic.LoadInt32(&l.active) == 1
+}
+
+// period returns tick of sampling, suppressed messages are reported once per tick
+func (l *limiter) period() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.tick
+}
+
+// allow returns empty reason if message should be logged, emergency messages are never dropped
+func (l *limiter) allow(level Level, template string, now time.Time) (reason string) {
+	if level == LevelEmergency || !l.enabled() {
+		return ""
+	}
+	key := limiterKey{level: level, template: template}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[key]
+	if !ok {
+		e = &limiterEntry{tokens: l.burst, last: now}
+		l.entries[key] = e
+	}
+	e.seen = now
+	defer func() {
+		if reason != "" {
+			e.suppressed++
+		}
+	}()
+	if rule, ok := l.rules[level]; ok {
+		if tick := now.Truncate(l.tick); !tick.Equal(e.tick) {
+			e.tick, e.count = tick, 0
+		}
+		e.count++
+		if e.count > rule.initial && (rule.thereafter == 0 || (e.count-rule.initial)%rule.thereafter != 0) {
+			return ReasonSampling
+		}
+	}
+	if l.rate > 0 {
+		e.tokens += now.Sub(e.last).Seconds() * l.rate
+		if e.tokens > l.burst {
+			e.tokens = l.burst
+		}
+		e.last = now
+		if e.tokens < 1 {
+			return ReasonRateLimit
+		}
+		e.tokens--
+	}
+	return ""
+}
+
+// drain returns counts of suppressed messages since the last call and forgets idle templates
+func (l *limiter) drain(now time.Time) []suppressed {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var result []suppressed
+	for key, e := range l.entries {
+		if e.suppressed > 0 {
+			result = append(result, suppressed{level: key.level, template: key.template, count: e.suppressed})
+			e.suppressed = 0
+			continue
+		}
+		if now.Sub(e.seen) > l.tick {
+			delete(l.entries, key)
+		}
+	}
+	return result
+}
+
+// unlimited returns func hook a logger for skipping sampling and rate limit
+func unlimited() Option {
+	return func(f *opts) error {
+		f.unlimited = true
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	l := newLimiter(&Config{
+		Sampling: SamplingCfg{
+			Initial:    2,
+			Thereafter: 3,
+			Levels:     []SamplingLevelCfg{{Level: LevelError}},
+		},
+		RateLimit: RateLimitCfg{Rate: 1, Burst: 2},
+	})
+
+	var passed []int
+	for i := 1; i <= 8; i++ {
+		if l.allow(LevelInfo, "info %v", now) == "" {
+			passed = append(passed, i)
+		}
+	}
+	// sampling passes 1, 2, 5, 8 and rate limit passes the first two of them
+	if len(passed) != 2 || passed[0] != 1 || passed[1] != 2 {
+		t.Errorf("unexpected passed messages: %v", passed)
+	}
+	if reason := l.allow(LevelError, "error", now); reason != "" {
+		t.Errorf("sampling is disabled for error level, but message dropped by %s", reason)
+	}
+	if reason := l.allow(LevelEmergency, "info %v", now); reason != "" {
+		t.Errorf("emergency message dropped by %s", reason)
+	}
+	if reason := l.allow(LevelInfo, "info %v", now.Add(3*time.Second)); reason != "" {
+		t.Errorf("message in the next tick dropped by %s", reason)
+	}
+
+	summary := l.drain(now.Add(3 * time.Second))
+	if len(summary) != 1 || summary[0].template != "info %v" || summary[0].count != 6 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if summary = l.drain(now.Add(10 * time.Second)); len(summary) != 0 || len(l.entries) != 0 {
+		t.Errorf("idle templates are not forgotten: %+v, %d", summary, len(l.entries))
+	}
+}
+
+func TestLimiterReload(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	cfg := &Config{Sampling: SamplingCfg{Initial: 1, Thereafter: 0}}
+	sh := newShared(cfg, nil, newMetrics())
+	l := sh.load().limiter
+	for i := 0; i < 3; i++ {
+		l.allow(LevelInfo, "info", now)
+	}
+
+	cfg.RateLimit = RateLimitCfg{Rate: 100}
+	if e := sh.store(cfg); e != nil {
+		t.Fatal(e)
+	}
+	if sh.load().limiter != l {
+		t.Fatal("limiter is replaced on reload")
+	}
+	// sampling state of tick is kept, so message is still sampled
+	if reason := l.allow(LevelInfo, "info", now); reason != ReasonSampling {
+		t.Errorf("unexpected reason after reload: %q", reason)
+	}
+	if summary := l.drain(now); len(summary) != 1 || summary[0].count != 3 {
+		t.Errorf("suppressed messages are lost on reload: %+v", summary)
+	}
+
+	cfg.Sampling.Disabled = true
+	cfg.RateLimit = RateLimitCfg{}
+	_ = sh.store(cfg)
+	if l.enabled() {
+		t.Error("limiter is enabled after rules are disabled")
+	}
+}
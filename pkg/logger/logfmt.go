@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const EncodingLogfmt = "logfmt"
+
+var logfmtPool = buffer.NewPool()
+
+func init() {
+	_ = zap.RegisterEncoder(EncodingLogfmt, func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return NewLogfmtEncoder(cfg), nil
+	})
+}
+
+// logfmtEncoder is zapcore.Encoder writes entries as key=value pairs, context fields are sorted by key
+type logfmtEncoder struct {
+	*zapcore.MapObjectEncoder
+	cfg zapcore.EncoderConfig
+}
+
+// NewLogfmtEncoder returns zapcore.Encoder for logfmt format
+func NewLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), cfg: cfg}
+}
+
+// Clone copies the encoder, ensuring that adding fields to the copy doesn't affect the original.
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), cfg: e.cfg}
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return clone
+}
+
+// EncodeEntry encodes an entry and fields, along with any accumulated context, into a byte buffer and returns it.
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.Clone().(*logfmtEncoder)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	buf := logfmtPool.Get()
+	if e.cfg.TimeKey != "" && e.cfg.EncodeTime != nil {
+		writeLogfmtPair(buf, e.cfg.TimeKey, encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) {
+			e.cfg.EncodeTime(ent.Time, enc)
+		}))
+	}
+	if e.cfg.LevelKey != "" && e.cfg.EncodeLevel != nil {
+		writeLogfmtPair(buf, e.cfg.LevelKey, encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) {
+			e.cfg.EncodeLevel(ent.Level, enc)
+		}))
+	}
+	if e.cfg.NameKey != "" && ent.LoggerName != "" {
+		writeLogfmtPair(buf, e.cfg.NameKey, ent.LoggerName)
+	}
+	if e.cfg.CallerKey != "" && ent.Caller.Defined {
+		writeLogfmtPair(buf, e.cfg.CallerKey, ent.Caller.TrimmedPath())
+	}
+	if e.cfg.MessageKey != "" {
+		writeLogfmtPair(buf, e.cfg.MessageKey, ent.Message)
+	}
+	keys := make([]string, 0, len(final.Fields))
+	for k := range final.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeLogfmtPair(buf, k, final.Fields[k])
+	}
+	if e.cfg.StacktraceKey != "" && ent.Stack != "" {
+		writeLogfmtPair(buf, e.cfg.StacktraceKey, ent.Stack)
+	}
+	lineEnding := e.cfg.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	buf.AppendString(lineEnding)
+	return buf, nil
+}
+
+// encodePrimitive returns first value appended by zapcore primitive encoder func, e.g. time or level encoder
+func encodePrimitive(fn func(enc zapcore.PrimitiveArrayEncoder)) interface{} {
+	m := zapcore.NewMapObjectEncoder()
+	_ = m.AddArray("v", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		fn(enc)
+		return nil
+	}))
+	if v, ok := m.Fields["v"].([]interface{}); ok && len(v) > 0 {
+		return v[0]
+	}
+	return nil
+}
+
+func writeLogfmtPair(buf *buffer.Buffer, key string, val interface{}) {
+	if buf.Len() > 0 {
+		buf.AppendByte(' ')
+	}
+	buf.AppendString(key)
+	buf.AppendByte('=')
+	s := fieldString(val)
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		s = fmt.Sprintf("%q", s)
+	}
+	buf.AppendString(s)
+}
+
+// fieldString returns text representation of field value, complex values are encoded as json
+func fieldString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		return v.Error()
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, uintptr, float32, float64:
+		return fmt.Sprint(v)
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+	return string(b)
+}
@@ -0,0 +1,90 @@
+package loggertest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// update rewrites golden files by actual output
+var update = flag.Bool("loggertest.update", false, "update golden files of loggertest")
+
+// AssertLogged fails test if there is no entry satisfying all matchers
+func AssertLogged(t testing.TB, r *Recorder, matchers ...Matcher) {
+	t.Helper()
+	if len(r.Filter(matchers...)) == 0 {
+		t.Errorf("expected entry is not logged, recorded:\n%s", dump(r.Entries()))
+	}
+}
+
+// AssertNotLogged fails test if there is entry satisfying all matchers
+func AssertNotLogged(t testing.TB, r *Recorder, matchers ...Matcher) {
+	t.Helper()
+	if found := r.Filter(matchers...); len(found) > 0 {
+		t.Errorf("unexpected entries are logged:\n%s", dump(found))
+	}
+}
+
+// AssertGolden compares structured output of recorded entries with golden file,
+// the file is rewritten if test is run with -loggertest.update flag
+func AssertGolden(t testing.TB, r *Recorder, path string) {
+	t.Helper()
+	actual := dump(r.Entries())
+	if *update {
+		if e := os.MkdirAll(filepath.Dir(path), 0755); e != nil {
+			t.Fatal(e)
+		}
+		if e := ioutil.WriteFile(path, actual, 0644); e != nil {
+			t.Fatal(e)
+		}
+		return
+	}
+	expected, e := ioutil.ReadFile(path)
+	if e != nil {
+		t.Fatalf("%v, run test with -loggertest.update flag to create golden file", e)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("output differs from golden file %s\nexpected:\n%s\nactual:\n%s", path, expected, actual)
+	}
+}
+
+// goldenEntry is a stable representation of entry, fields are sorted by json encoder
+type goldenEntry struct {
+	Level   string                 `json:"level"`
+	Name    string                 `json:"name,omitempty"`
+	Message string                 `json:"msg"`
+	Tags    []string               `json:"tags,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// dump returns entries as json lines
+func dump(entries []Entry) []byte {
+	buf := &bytes.Buffer{}
+	for _, e := range entries {
+		ge := goldenEntry{Level: e.Level.String(), Name: e.Name, Message: e.Message, Tags: e.Tags}
+		if len(e.Fields) > 0 {
+			ge.Fields = make(map[string]interface{}, len(e.Fields))
+			for k, v := range e.Fields {
+				ge.Fields[k] = jsonValue(v)
+			}
+		}
+		b, _ := json.Marshal(ge)
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// jsonValue returns value which can be encoded into json, fmt representation is used otherwise
+func jsonValue(v interface{}) interface{} {
+	if _, e := json.Marshal(v); e != nil {
+		return strings.TrimSpace(fmt.Sprint(v))
+	}
+	return v
+}
@@ -0,0 +1,149 @@
+package loggertest
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+)
+
+// Entry is a recorded log entry
+type Entry struct {
+	logger.Entity
+	// Message is a format with replaced args
+	Message string
+}
+
+// Matcher returns true if entry satisfies condition
+type Matcher func(e Entry) bool
+
+// Recorder is the logger keeps entries in memory, instance and its children share entries
+type Recorder struct {
+	logger.Logger
+	mu      sync.Mutex
+	entries []Entry
+}
+
+// NewRecorder returns recorder implemented of Logger interface, cfg may be nil
+func NewRecorder(cfg *logger.Config) *Recorder {
+	if cfg == nil {
+		cfg = &logger.Config{}
+	}
+	r := &Recorder{}
+	r.Logger = logger.NewMockFunc(context.Background(), cfg, r.record)
+	return r
+}
+
+func (r *Recorder) record(e logger.Entity) {
+	entry := Entry{Entity: e, Message: e.Format}
+	if len(e.Args) > 0 {
+		entry.Message = fmt.Sprintf(e.Format, e.Args...)
+	}
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+}
+
+// Entries returns copy of recorded entries in order of logging
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+	return entries
+}
+
+// Filter returns entries satisfying all matchers
+func (r *Recorder) Filter(matchers ...Matcher) []Entry {
+	var entries []Entry
+	for _, e := range r.Entries() {
+		if Match(e, matchers...) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Len returns count of recorded entries
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Reset removes recorded entries
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Match returns true if entry satisfies all matchers
+func Match(e Entry, matchers ...Matcher) bool {
+	for _, m := range matchers {
+		if !m(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// ByLevel matches entries of level
+func ByLevel(level logger.Level) Matcher {
+	return func(e Entry) bool {
+		return e.Level == level
+	}
+}
+
+// ByLevelAtLeast matches entries of level or more severe ones
+func ByLevelAtLeast(level logger.Level) Matcher {
+	return func(e Entry) bool {
+		return e.Level <= level
+	}
+}
+
+// ByMessage matches entries which message matches regular expression, it panics if expression is invalid
+func ByMessage(expr string) Matcher {
+	re := regexp.MustCompile(expr)
+	return func(e Entry) bool {
+		return re.MatchString(e.Message)
+	}
+}
+
+// ByField matches entries with field equal to value, values are compared by fmt representation
+func ByField(key string, value interface{}) Matcher {
+	return func(e Entry) bool {
+		v, ok := e.Fields[key]
+		return ok && fmt.Sprint(v) == fmt.Sprint(value)
+	}
+}
+
+// HasField matches entries with field
+func HasField(key string) Matcher {
+	return func(e Entry) bool {
+		_, ok := e.Fields[key]
+		return ok
+	}
+}
+
+// ByTag matches entries with tag
+func ByTag(tag string) Matcher {
+	return func(e Entry) bool {
+		for _, t := range e.Tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ByName matches entries of named logger or its children
+func ByName(name string) Matcher {
+	return func(e Entry) bool {
+		return e.Name == name || strings.HasPrefix(e.Name, name+logger.NameSep)
+	}
+}
@@ -0,0 +1,62 @@
+package loggertest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(&logger.Config{Mask: logger.MaskCfg{Fields: []string{"password"}}})
+	child := r.WithFields(logger.Fields{"order": 42}).Named("billing").WithTags(logger.Tags{"payments"})
+	child.Info("charged %d cents", logger.Args(100), logger.WithFields(logger.Fields{"password": "secret"}))
+	r.Warning("retry", logger.PairArgs("attempt", 2))
+	r.Error("failed", logger.String("reason", "timeout"))
+
+	AssertLogged(t, r, ByLevel(logger.LevelInfo), ByMessage(`^charged \d+ cents$`), ByName("billing"))
+	AssertLogged(t, r, ByTag("payments"), ByField("order", 42), ByField("password", "***"))
+	AssertLogged(t, r, ByLevel(logger.LevelWarning), ByField("attempt", 2))
+	AssertNotLogged(t, r, ByLevel(logger.LevelDebug))
+	AssertNotLogged(t, r, ByMessage("secret"))
+	if n := len(r.Filter(ByLevelAtLeast(logger.LevelWarning))); n != 2 {
+		t.Errorf("expected 2 warnings and errors, got %d", n)
+	}
+	AssertGolden(t, r, filepath.Join("testdata", "recorder.golden"))
+
+	r.Reset()
+	if r.Len() != 0 {
+		t.Errorf("entries are not removed")
+	}
+}
+
+func TestRecorderParallel(t *testing.T) {
+	r := NewRecorder(nil)
+	const workers, count = 8, 100
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < workers; i++ {
+			i := i
+			t.Run(fmt.Sprint(i), func(t *testing.T) {
+				t.Parallel()
+				child := r.WithFields(logger.Fields{"worker": i})
+				var wg sync.WaitGroup
+				for j := 0; j < count; j++ {
+					wg.Add(1)
+					go func(j int) {
+						defer wg.Done()
+						child.Debug("entry %d", logger.Args(j))
+					}(j)
+				}
+				wg.Wait()
+				if n := len(r.Filter(ByField("worker", i))); n != count {
+					t.Errorf("expected %d entries of worker, got %d", count, n)
+				}
+			})
+		}
+	})
+	if r.Len() != workers*count {
+		t.Errorf("expected %d entries, got %d", workers*count, r.Len())
+	}
+}
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	MaskCard           = "card"
+	MaskEmail          = "email"
+	MaskJWT            = "jwt"
+	defaultReplacement = "***"
+)
+
+var (
+	ErrUnknownMask = errors.New("unknown builtin mask")
+
+	builtinMasks = map[string]string{
+		MaskCard:  `\b(?:\d[ -]?){12,18}\d\b`,
+		MaskEmail: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+		MaskJWT:   `\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`,
+	}
+)
+
+// MaskCfg is a setting of masking of sensitive data in messages and fields
+type MaskCfg struct {
+	// Fields are names of fields which values are masked entirely, names are case insensitive
+	Fields []string
+	// Patterns are regular expressions, matches are masked in messages and string values
+	Patterns []string
+	// Builtin are names of predefined patterns: card, email or jwt
+	Builtin []string
+	// Replacement is a text of masked values
+	Replacement string `default:"***"`
+}
+
+// SensitiveValue is a value which is always masked in log entries
+type SensitiveValue struct {
+	Value interface{}
+}
+
+// Sensitive wraps value for masking in fields and args
+func Sensitive(v interface{}) SensitiveValue {
+	return SensitiveValue{Value: v}
+}
+
+// String implements interface Stringer
+func (v SensitiveValue) String() string {
+	return defaultReplacement
+}
+
+// Format implements interface Formatter, value is never printed
+func (v SensitiveValue) Format(f fmt.State, c rune) {
+	_, _ = f.Write([]byte(defaultReplacement))
+}
+
+// MarshalJSON
+func (v SensitiveValue) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + defaultReplacement + `"`), nil
+}
+
+// masker replaces sensitive data by configured rules
+type masker struct {
+	names       map[string]struct{}
+	re          *regexp.Regexp
+	replacement string
+}
+
+// newMasker returns masker of valid rules and error describing invalid ones
+func newMasker(cfg MaskCfg) (*masker, error) {
+	m := &masker{names: map[string]struct{}{}, replacement: cfg.Replacement}
+	if m.replacement == "" {
+		m.replacement = defaultReplacement
+	}
+	for _, name := range cfg.Fields {
+		m.names[strings.ToLower(name)] = struct{}{}
+	}
+	var (
+		patterns []string
+		errs     []string
+	)
+	for _, name := range cfg.Builtin {
+		if p, ok := builtinMasks[name]; ok {
+			patterns = append(patterns, p)
+		} else {
+			errs = append(errs, errors.WithMessage(ErrUnknownMask, name).Error())
+		}
+	}
+	for _, p := range cfg.Patterns {
+		if _, e := regexp.Compile(p); e != nil {
+			errs = append(errs, e.Error())
+			continue
+		}
+		patterns = append(patterns, p)
+	}
+	if len(patterns) > 0 {
+		m.re = regexp.MustCompile("(?:" + strings.Join(patterns, ")|(?:") + ")")
+	}
+	if len(errs) > 0 {
+		return m, errors.WithMessage(errors.New(strings.Join(errs, "; ")), Prefix+": mask")
+	}
+	return m, nil
+}
+
+// text masks matches of patterns
+func (m *masker) text(s string) string {
+	if m.re == nil {
+		return s
+	}
+	return m.re.ReplaceAllLiteralString(s, m.replacement)
+}
+
+// name returns true if values of field should be masked entirely
+func (m *masker) name(key string) bool {
+	if len(m.names) == 0 {
+		return false
+	}
+	_, ok := m.names[strings.ToLower(key)]
+	return ok
+}
+
+// args returns args with replaced sensitive values and masked strings, slice is copied on change
+func (m *masker) args(args []interface{}) []interface{} {
+	var masked []interface{}
+	for i, arg := range args {
+		val, ok := m.mask("", arg)
+		if !ok {
+			continue
+		}
+		if masked == nil {
+			masked = make([]interface{}, len(args))
+			copy(masked, args)
+		}
+		masked[i] = val
+	}
+	if masked == nil {
+		return args
+	}
+	return masked
+}
+
+// fields masks zap fields in place, stringers are evaluated eagerly when patterns are set
+func (m *masker) fields(fields []zapcore.Field) {
+	for i, f := range fields {
+		if f.Key == fieldTags {
+			continue
+		}
+		if m.name(f.Key) {
+			fields[i] = zap.String(f.Key, m.replacement)
+			continue
+		}
+		if _, ok := f.Interface.(SensitiveValue); ok {
+			fields[i] = zap.String(f.Key, m.replacement)
+			continue
+		}
+		if m.re == nil {
+			continue
+		}
+		switch f.Type {
+		case zapcore.StringType:
+			fields[i].String = m.text(f.String)
+		case zapcore.StringerType:
+			fields[i] = zap.String(f.Key, m.text(f.Interface.(fmt.Stringer).String()))
+		}
+	}
+}
+
+// value returns masked value of field, it's used by Mock
+func (m *masker) value(key string, v interface{}) interface{} {
+	masked, _ := m.mask(key, v)
+	return masked
+}
+
+// mask returns masked value and true if value is changed
+func (m *masker) mask(key string, v interface{}) (interface{}, bool) {
+	if _, ok := v.(SensitiveValue); ok || m.name(key) {
+		return m.replacement, true
+	}
+	if s, ok := v.(string); ok && m.re != nil {
+		if masked := m.text(s); masked != s {
+			return masked, true
+		}
+	}
+	return v, false
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"github.com/uber-go/tally"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	MetricWriteErrors  = "write_errors"
+	MetricRotateErrors = "rotate_errors"
+	MetricTagSink      = "sink"
+)
+
+// MetricAware is implemented by loggers which export own metrics, e.g. count of write errors
+type MetricAware interface {
+	// SetMetric sets scope for own metrics of logger
+	SetMetric(scope tally.Scope)
+}
+
+// metrics holds scope for own metrics of logger, it's shared between instance and its children
+type metrics struct {
+	scope atomic.Value
+}
+
+func newMetrics() *metrics {
+	m := &metrics{}
+	m.set(tally.NoopScope)
+	return m
+}
+
+func (m *metrics) set(scope tally.Scope) {
+	m.scope.Store(scope)
+}
+
+func (m *metrics) load() tally.Scope {
+	if m == nil {
+		return tally.NoopScope
+	}
+	return m.scope.Load().(tally.Scope)
+}
+
+func (m *metrics) inc(name string, tags map[string]string) {
+	m.load().Tagged(tags).Counter(name).Inc(1)
+}
+
+// countingCore is zapcore.Core counts write errors of underlying sink
+type countingCore struct {
+	zapcore.Core
+	metrics *metrics
+	sink    string
+}
+
+// With adds structured context to the Core.
+func (c *countingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &countingCore{Core: c.Core.With(fields), metrics: c.metrics, sink: c.sink}
+}
+
+// Check determines whether the supplied Entry should be logged.
+func (c *countingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write serializes the Entry and any Fields supplied at the log site and writes them to their destination.
+func (c *countingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	e := c.Core.Write(ent, fields)
+	if e != nil {
+		c.metrics.inc(MetricWriteErrors, map[string]string{MetricTagSink: c.sink})
+	}
+	return e
+}
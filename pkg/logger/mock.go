@@ -2,11 +2,14 @@ package logger
 
 import (
 	"context"
+	"runtime/debug"
+	"sync/atomic"
 )
 
 // Entity represent log struct with all assets
 type Entity struct {
 	Level  Level
+	Name   string
 	Fields Fields
 	Args   []interface{}
 	Tags   Tags
@@ -17,10 +20,14 @@ type Entity struct {
 type Mock struct {
 	ctx     context.Context
 	ch      chan Entity
+	handler func(Entity)
 	discard bool
 	cfg     *Config
 	tags    []string
 	fields  map[string]interface{}
+	name    string
+	// masker is shared with children and replaced on reload
+	masker *atomic.Value
 }
 
 // Printf is like fmt.Printf, push to log entry with debug level
@@ -81,7 +88,7 @@ func (m *Mock) Write(p []byte) (n int, err error) {
 
 // Log push to log with specified level
 func (m *Mock) Log(level Level, format string, o ...Option) {
-	if !m.discard {
+	if !m.discard && m.handler == nil {
 		return
 	}
 	opts := &opts{}
@@ -107,13 +114,33 @@ func (m *Mock) Log(level Level, format string, o ...Option) {
 	for k, v := range opts.fields {
 		fields[k] = v
 	}
-	m.ch <- Entity{
+	for k, v := range fieldsMap(pairFields(opts.zfields, opts.wargs)) {
+		fields[k] = v
+	}
+	if opts.stack != nil {
+		fields[*opts.stack] = string(debug.Stack())
+	}
+	for k, v := range opts.pfields {
+		fields[k] = v
+	}
+	// masking
+	mask := m.masker.Load().(*masker)
+	for k, v := range fields {
+		fields[k] = mask.value(k, v)
+	}
+	entity := Entity{
 		Level:  level,
-		Format: format,
-		Args:   opts.args,
+		Name:   m.name,
+		Format: mask.text(format),
+		Args:   mask.args(opts.args),
 		Fields: fields,
 		Tags:   tags,
 	}
+	if m.handler != nil {
+		m.handler(entity)
+		return
+	}
+	m.ch <- entity
 }
 
 // WithFields create new instance with fields
@@ -130,6 +157,14 @@ func (m *Mock) WithTags(tags Tags) Logger {
 	return nm
 }
 
+// Named create new instance with name joined to the current one, e.g. billing.stripe
+func (m *Mock) Named(name string) Logger {
+	nm := &Mock{}
+	copyMock(nm, m, nil, nil)
+	nm.name = joinName(m.name, name)
+	return nm
+}
+
 func copyMock(dst, src *Mock, tags []string, fields map[string]interface{}) {
 	// tags
 	cTags := make([]string, len(src.tags))
@@ -147,6 +182,11 @@ func copyMock(dst, src *Mock, tags []string, fields map[string]interface{}) {
 	for k, v := range fields {
 		dst.fields[k] = v
 	}
+	dst.name = src.name
+	dst.masker = src.masker
+	// children of handled mock share handler, channel of mock isn't shared
+	dst.handler = src.handler
+	dst.cfg = src.cfg
 }
 
 // Catch returns channel of entity structure for testing event content
@@ -157,10 +197,27 @@ func (m *Mock) Catch() <-chan Entity {
 // NewMock returns mock instance implemented of Logger interface
 func NewMock(ctx context.Context, cfg *Config, discard bool) *Mock {
 	copyCfg := *cfg
+	mask := &atomic.Value{}
+	m, _ := newMasker(cfg.Mask)
+	mask.Store(m)
+	if cfg.invoker != nil {
+		cfg.OnReload(func(ctx context.Context) {
+			m, _ := newMasker(cfg.loaded().Mask)
+			mask.Store(m)
+		})
+	}
 	return &Mock{
 		ctx:     ctx,
 		cfg:     &copyCfg,
+		masker:  mask,
 		ch:      make(chan Entity),
 		discard: discard,
 	}
 }
+
+// NewMockFunc returns mock instance passes entities into fn, children of instance share fn
+func NewMockFunc(ctx context.Context, cfg *Config, fn func(Entity)) *Mock {
+	m := NewMock(ctx, cfg, false)
+	m.handler = fn
+	return m
+}
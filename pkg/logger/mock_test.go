@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMockStack(t *testing.T) {
+	var entity Entity
+	mock := NewMockFunc(context.Background(), &Config{}, func(e Entity) {
+		entity = e
+	})
+	mock.Error("failed", Stack("stack"))
+	if stack, _ := entity.Fields["stack"].(string); !strings.Contains(stack, "TestMockStack") {
+		t.Errorf("stack isn't added to fields of entity: %q", stack)
+	}
+	mock.Error("failed")
+	if _, ok := entity.Fields["stack"]; ok {
+		t.Error("stack is added without option")
+	}
+	mock.Error("failed", Err(errors.New("declined")))
+	if stack := fmt.Sprint(entity.Fields[FieldErrorStack]); !strings.Contains(stack, "TestMockStack") {
+		t.Errorf("stack of error isn't added to fields of entity: %q", stack)
+	}
+}
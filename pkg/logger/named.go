@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// NameSep separates components of logger name, e.g. billing.stripe
+	NameSep = "."
+	// LevelsSelfKey is a key of level of component in nested map of levels, e.g. {billing: {_: debug, stripe: info}}
+	LevelsSelfKey = "_"
+)
+
+// namedLogger is zap logger with name cached for the current output
+type namedLogger struct {
+	base   *zap.Logger
+	logger *zap.Logger
+}
+
+// NestedLevelsHookFunc returns decoder func hook for flattening nested maps of levels into dotted names,
+// configurator splits dotted keys, so billing.stripe is decoded as nested map
+func NestedLevelsHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.Map || t != reflect.TypeOf(map[string]Level{}) {
+			return data, nil
+		}
+		flat := map[string]interface{}{}
+		flattenLevels(flat, "", reflect.ValueOf(data))
+		return flat, nil
+	}
+}
+
+func flattenLevels(dst map[string]interface{}, prefix string, v reflect.Value) {
+	for _, k := range v.MapKeys() {
+		name, _ := k.Interface().(string)
+		if name == LevelsSelfKey {
+			name = ""
+		}
+		name = joinName(prefix, name)
+		val := v.MapIndex(k)
+		for val.Kind() == reflect.Interface {
+			val = val.Elem()
+		}
+		if val.Kind() == reflect.Map {
+			flattenLevels(dst, name, val)
+		} else if val.IsValid() {
+			dst[name] = val.Interface()
+		}
+	}
+}
+
+// joinName returns name of child logger
+func joinName(parent, name string) string {
+	switch {
+	case parent == "":
+		return name
+	case name == "":
+		return parent
+	}
+	return parent + NameSep + name
+}
+
+// nameLevels returns overrides of levels by names, names are case insensitive
+func nameLevels(levels map[string]Level) map[string]Level {
+	if len(levels) == 0 {
+		return nil
+	}
+	m := make(map[string]Level, len(levels))
+	for name, l := range levels {
+		m[strings.ToLower(name)] = l
+	}
+	return m
+}
+
+// nameLevel returns level of named logger resolved by the longest prefix of name, general level is used if not set
+func (s *settings) nameLevel(name string) Level {
+	if len(s.levels) == 0 {
+		return s.Level
+	}
+	name = strings.ToLower(name)
+	for name != "" {
+		if l, ok := s.levels[name]; ok {
+			return l
+		}
+		i := strings.LastIndex(name, NameSep)
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return s.Level
+}
+
+// enabled returns true if entries of level are passed by sinks with own levels or by level of named logger
+func (s *settings) enabled(name string, level Level) bool {
+	return level <= s.sinksLevel || level <= s.nameLevel(name)
+}
+
+// generalLevel returns the most verbose level of general level and overrides of named loggers
+func generalLevel(cfg *Config) Level {
+	level := cfg.Level
+	for _, l := range cfg.Levels {
+		if l > level {
+			level = l
+		}
+	}
+	return level
+}
+
+// namedLevelCore is zapcore.Core of sink without own level, entries are checked by level of named logger
+type namedLevelCore struct {
+	zapcore.Core
+	shared *shared
+}
+
+// With adds structured context to the Core.
+func (c *namedLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &namedLevelCore{Core: c.Core.With(fields), shared: c.shared}
+}
+
+// Check determines whether the supplied Entry should be logged.
+func (c *namedLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if levelFromZap(ent.Level) > c.shared.load().nameLevel(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
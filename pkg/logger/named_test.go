@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/config"
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+)
+
+func TestNamedLevels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+
+	inv := invoker.NewInvoker()
+	var initial = config.Initial{
+		Viper: config.NewViper(),
+	}
+	initial.Viper.Set("logger.level", "warning")
+	initial.Viper.Set("logger.levels", map[string]interface{}{"billing": map[string]interface{}{"_": "debug", "stripe": "info"}, "payments.paypal": "info"})
+	initial.Viper.Set("logger.disableRedirectStdLog", true)
+	initial.Viper.Set("logger.sinks", []interface{}{map[string]interface{}{"type": SinkFile, "path": path}})
+	configurator, _, err := config.Provider(initial, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := ProviderCfg(configurator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	zap := NewZap(ctx, cfg)
+	billing := zap.Named("billing")
+	stripe := billing.Named("Stripe")
+
+	billing.Debug("billing debug")
+	stripe.Debug("stripe debug")
+	stripe.Named("api").Info("stripe api info")
+	zap.Named("billingx").Info("billingx info")
+	zap.Info("root info")
+	zap.Named("payments.PayPal").Info("paypal info")
+
+	initial.Viper.Set("logger.levels", map[string]interface{}{"billing": "error"})
+	inv.Reload(ctx)
+	for deadline := time.Now().Add(5 * time.Second); zap.shared.load().nameLevel("billing") != LevelError; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("config is not reloaded")
+		}
+	}
+	billing.Warning("billing warning")
+	stripe.Error("stripe error")
+	_ = zap.Sync()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, msg := range []string{"billing debug", "stripe api info", `"logger":"billing.Stripe.api"`, "stripe error", "paypal info"} {
+		if !strings.Contains(out, msg) {
+			t.Errorf("%q is not written: %s", msg, out)
+		}
+	}
+	for _, msg := range []string{"stripe debug", "billingx info", "root info", "billing warning"} {
+		if strings.Contains(out, msg) {
+			t.Errorf("%q is written: %s", msg, out)
+		}
+	}
+}
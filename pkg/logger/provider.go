@@ -18,7 +18,7 @@ func ProviderCfg(cfg config.Configurator) (*Config, func(), error) {
 	c := &Config{
 		invoker: invoker.NewInvoker(),
 	}
-	e := cfg.UnmarshalKeyOnReload(UnmarshalKey, c, StringToLoggerLevelHookFunc())
+	e := cfg.UnmarshalKeyOnReload(UnmarshalKey, c, StringToLoggerLevelHookFunc(), NestedLevelsHookFunc())
 	return c, func() {}, e
 }
 
@@ -2,6 +2,10 @@ package logger
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,3 +38,166 @@ func TestReload(t *testing.T) {
 	zap.Log(LevelInfo, "some")
 	time.Sleep(time.Second * 5)
 }
+
+func TestReloadLevel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+
+	inv := invoker.NewInvoker()
+	var initial = config.Initial{
+		Viper: config.NewViper(),
+	}
+	initial.Viper.Set("logger.level", "warning")
+	initial.Viper.Set("logger.disableRedirectStdLog", true)
+	initial.Viper.Set("logger.sinks", []interface{}{map[string]interface{}{"type": SinkFile, "path": path}})
+	configurator, _, err := config.Provider(initial, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := ProviderCfg(configurator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	zap := NewZap(ctx, cfg)
+	child := zap.WithTags(Tags{"child"})
+
+	child.Info("before reload")
+	initial.Viper.Set("logger.level", "info")
+	inv.Reload(ctx)
+	for deadline := time.Now().Add(5 * time.Second); zap.shared.load().Level != LevelInfo; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("config is not reloaded")
+		}
+	}
+	child.Info("after reload")
+	_ = zap.shared.out.load().Sync()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), "before reload") || !strings.Contains(string(b), "after reload") {
+		t.Errorf("unexpected output after reload: %s", b)
+	}
+}
+
+func TestReloadFilter(t *testing.T) {
+	inv := invoker.NewInvoker()
+	var initial = config.Initial{
+		Viper: config.NewViper(),
+	}
+	initial.Viper.Set("logger.level", "debug")
+	initial.Viper.Set("logger.filter", "tag:payments")
+	initial.Viper.Set("logger.disableRedirectStdLog", true)
+	configurator, _, err := config.Provider(initial, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := ProviderCfg(configurator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	zap := NewZap(ctx, cfg)
+	entry := &filterEntry{level: LevelWarning, tags: []string{"billing"}}
+	if zap.shared.load().filter.match(entry) {
+		t.Fatal("entry is matched by initial filter")
+	}
+
+	initial.Viper.Set("logger.filter", "tag:payments OR level<=warning")
+	inv.Reload(ctx)
+	for deadline := time.Now().Add(5 * time.Second); !zap.shared.load().filter.match(entry); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("filter is not reloaded")
+		}
+	}
+}
+
+func TestReloadReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+
+	inv := invoker.NewInvoker()
+	var initial = config.Initial{
+		Viper: config.NewViper(),
+	}
+	initial.Viper.Set("logger.level", "info")
+	initial.Viper.Set("logger.disableRedirectStdLog", true)
+	initial.Viper.Set("logger.sinks", []interface{}{map[string]interface{}{"type": SinkFile, "path": path}})
+	configurator, _, err := config.Provider(initial, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := ProviderCfg(configurator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	zap := NewZap(ctx, cfg)
+
+	zap.Info("before rename")
+	// external rotation, file is reopened on reload with unchanged sinks
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	inv.Reload(ctx)
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		zap.Info("after reload")
+		_ = zap.shared.out.load().Sync()
+		if b, err := ioutil.ReadFile(path); err == nil && strings.Contains(string(b), "after reload") {
+			if strings.Contains(string(b), "before rename") {
+				t.Errorf("entry before rename is written into new file: %s", b)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("file is not reopened on reload")
+		}
+	}
+	if b, err := ioutil.ReadFile(path + ".1"); err != nil || !strings.Contains(string(b), "before rename") {
+		t.Fatalf("unexpected content of renamed file: %q, %v", b, err)
+	}
+}
+
+func TestReloadDebug(t *testing.T) {
+	inv := invoker.NewInvoker()
+	var initial = config.Initial{
+		Viper: config.NewViper(),
+	}
+	initial.Viper.Set("logger.level", "info")
+	initial.Viper.Set("logger.disableRedirectStdLog", true)
+	configurator, _, err := config.Provider(initial, inv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, _, err := ProviderCfg(configurator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	zap := NewZap(ctx, cfg)
+
+	initial.Viper.Set("logger.debug", true)
+	inv.Reload(ctx)
+	for deadline := time.Now().Add(5 * time.Second); zap.shared.load().Level != LevelDebug; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("debug mode is not reloaded")
+		}
+	}
+	if !cfg.Debug || cfg.Level != LevelInfo {
+		t.Errorf("debug overrides are applied to shared config: debug %v, level %s", cfg.Debug, cfg.Level)
+	}
+}
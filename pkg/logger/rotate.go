@@ -0,0 +1,332 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	compressSuffix   = ".gz"
+	megabyte         = 1024 * 1024
+)
+
+// RotateCfg is a setting of file rotation, rotation is disabled when MaxSize and Every are not set
+type RotateCfg struct {
+	// MaxSize is a maximum size in megabytes of file before it gets rotated
+	MaxSize int
+	// Every rotates file at the start of every period, e.g. 24h
+	Every time.Duration
+	// MaxBackups is a maximum number of rotated files to retain, all files are retained if zero
+	MaxBackups int
+	// MaxAge is a maximum time to retain rotated files, files are not removed by age if zero
+	MaxAge time.Duration
+	// Compress rotated files with gzip
+	Compress bool
+	// LocalTime uses local time in names of rotated files and boundaries of periods instead of UTC
+	LocalTime bool
+	// ReopenOnSignal reopens file on SIGHUP for external rotation tools, it disables default termination
+	// of process by SIGHUP, file is reopened on reload of config anyway
+	ReopenOnSignal bool
+}
+
+// rotateFile is zapcore.WriteSyncer writes into file with rotation by size and time,
+// file is reopened on SIGHUP for external rotation tools if it's enabled
+type rotateFile struct {
+	mu       sync.Mutex
+	path     string
+	cfg      RotateCfg
+	loc      *time.Location
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	metrics  *metrics
+	millCh   chan struct{}
+	sigCh    chan os.Signal
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newRotateFile(path string, cfg RotateCfg, m *metrics) (*rotateFile, error) {
+	f := &rotateFile{
+		path:    path,
+		cfg:     cfg,
+		loc:     time.UTC,
+		metrics: m,
+		millCh:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if cfg.LocalTime {
+		f.loc = time.Local
+	}
+	if e := f.open(); e != nil {
+		return nil, e
+	}
+	if cfg.ReopenOnSignal {
+		f.sigCh = make(chan os.Signal, 1)
+		signal.Notify(f.sigCh, syscall.SIGHUP)
+	}
+	f.wg.Add(1)
+	go f.run()
+	return f, nil
+}
+
+func (f *rotateFile) run() {
+	defer f.wg.Done()
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-f.sigCh:
+			if e := f.Reopen(); e != nil {
+				f.metrics.inc(MetricRotateErrors, map[string]string{MetricTagSink: SinkFile})
+			}
+		case <-f.millCh:
+			if e := f.mill(); e != nil {
+				f.metrics.inc(MetricRotateErrors, map[string]string{MetricTagSink: SinkFile})
+			}
+		}
+	}
+}
+
+func (f *rotateFile) now() time.Time {
+	return time.Now().In(f.loc)
+}
+
+func (f *rotateFile) open() error {
+	if e := os.MkdirAll(filepath.Dir(f.path), 0755); e != nil {
+		return e
+	}
+	file, e := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if e != nil {
+		return e
+	}
+	info, e := file.Stat()
+	if e != nil {
+		_ = file.Close()
+		return e
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = f.now()
+	return nil
+}
+
+// Write
+func (f *rotateFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if e := f.open(); e != nil {
+			return 0, e
+		}
+	}
+	if f.shouldRotate(int64(len(p))) {
+		if e := f.rotate(); e != nil {
+			return 0, e
+		}
+	}
+	n, e := f.file.Write(p)
+	f.size += int64(n)
+	return n, e
+}
+
+func (f *rotateFile) shouldRotate(n int64) bool {
+	if f.cfg.MaxSize > 0 && f.size > 0 && f.size+n > int64(f.cfg.MaxSize)*megabyte {
+		return true
+	}
+	if f.cfg.Every > 0 && !f.periodStart(f.now()).Equal(f.periodStart(f.openedAt)) {
+		return true
+	}
+	return false
+}
+
+// periodStart returns start of rotation period of time, periods are aligned to midnight of location of file
+// instead of UTC midnight
+func (f *rotateFile) periodStart(t time.Time) time.Time {
+	_, offset := t.In(f.loc).Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(f.cfg.Every).Add(-shift)
+}
+
+// rotate renames current file to backup and opens new one, compression and cleanup are made in background
+func (f *rotateFile) rotate() error {
+	if e := f.file.Close(); e != nil {
+		return e
+	}
+	f.file = nil
+	if e := os.Rename(f.path, f.backupName()); e != nil && !os.IsNotExist(e) {
+		return e
+	}
+	if e := f.open(); e != nil {
+		return e
+	}
+	select {
+	case f.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// backupName returns free name of rotated file, names rotated in the same millisecond get ordinal suffix
+func (f *rotateFile) backupName() string {
+	ext := filepath.Ext(f.path)
+	name := strings.TrimSuffix(f.path, ext) + "-" + f.now().Format(backupTimeFormat)
+	backup := name + ext
+	for n := 1; exists(backup) || exists(backup+compressSuffix); n++ {
+		backup = name + "-" + strconv.Itoa(n) + ext
+	}
+	return backup
+}
+
+func exists(path string) bool {
+	_, e := os.Lstat(path)
+	return !os.IsNotExist(e)
+}
+
+// Reopen closes and opens file by the same path, it's useful after rotation by external tools
+func (f *rotateFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		_ = f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+type backupFile struct {
+	path string
+	at   time.Time
+	n    int
+}
+
+// parseBackup parses time and ordinal of rotated file from name without prefix and extension
+func parseBackup(name string, loc *time.Location) (time.Time, int, bool) {
+	if at, e := time.ParseInLocation(backupTimeFormat, name, loc); e == nil {
+		return at, 0, true
+	}
+	i := strings.LastIndex(name, "-")
+	if i < 0 {
+		return time.Time{}, 0, false
+	}
+	n, e := strconv.Atoi(name[i+1:])
+	if e != nil || n < 1 {
+		return time.Time{}, 0, false
+	}
+	at, e := time.ParseInLocation(backupTimeFormat, name[:i], loc)
+	if e != nil {
+		return time.Time{}, 0, false
+	}
+	return at, n, true
+}
+
+// mill compresses rotated files and removes ones exceeded by count or age
+func (f *rotateFile) mill() error {
+	dir := filepath.Dir(f.path)
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(filepath.Base(f.path), ext) + "-"
+	infos, e := ioutil.ReadDir(dir)
+	if e != nil {
+		return e
+	}
+	var backups []backupFile
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)
+		at, n, ok := parseBackup(strings.TrimPrefix(ts, prefix), f.loc)
+		if !ok {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), at: at, n: n})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].at.Equal(backups[j].at) {
+			return backups[i].n > backups[j].n
+		}
+		return backups[i].at.After(backups[j].at)
+	})
+	var (
+		err    error
+		cutoff = f.now().Add(-f.cfg.MaxAge)
+	)
+	for i, b := range backups {
+		if (f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups) || (f.cfg.MaxAge > 0 && b.at.Before(cutoff)) {
+			if e := os.Remove(b.path); e != nil {
+				err = e
+			}
+			continue
+		}
+		if f.cfg.Compress && !strings.HasSuffix(b.path, compressSuffix) {
+			if e := compressFile(b.path); e != nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+func compressFile(path string) error {
+	src, e := os.Open(path)
+	if e != nil {
+		return e
+	}
+	defer src.Close()
+	dst, e := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if e != nil {
+		return e
+	}
+	gz := gzip.NewWriter(dst)
+	if _, e = io.Copy(gz, src); e == nil {
+		e = gz.Close()
+	}
+	if ce := dst.Close(); e == nil {
+		e = ce
+	}
+	if e != nil {
+		_ = os.Remove(path + compressSuffix)
+		return e
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// Sync
+func (f *rotateFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// Close stops listening of signals and closes file
+func (f *rotateFile) Close() error {
+	if f.sigCh != nil {
+		signal.Stop(f.sigCh)
+	}
+	close(f.done)
+	f.wg.Wait()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	e := f.file.Close()
+	f.file = nil
+	return e
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "app.log")
+	f, err := newRotateFile(path, RotateCfg{MaxSize: 1, MaxBackups: 1, Compress: true}, newMetrics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	chunk := bytes.Repeat([]byte("x"), megabyte/2+1)
+	for i := 0; i < 5; i++ {
+		if _, err := f.Write(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var names []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		infos, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = names[:0]
+		for _, info := range infos {
+			names = append(names, info.Name())
+		}
+		if len(names) == 2 && strings.HasSuffix(names[0], compressSuffix) {
+			break
+		}
+	}
+	if len(names) != 2 || !strings.HasPrefix(names[0], "app-") || !strings.HasSuffix(names[0], ".log"+compressSuffix) || names[1] != "app.log" {
+		t.Fatalf("unexpected files after rotation: %v", names)
+	}
+
+	// external rotation
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("after reopen\n")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil || string(b) != "after reopen\n" {
+		t.Fatalf("unexpected content after reopen: %q, %v", b, err)
+	}
+}
+
+func TestRotateFileBackups(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// local zone far from UTC, backups named by local time are outdated if they are parsed as UTC
+	local := time.Local
+	time.Local = time.FixedZone("test", -12*60*60)
+	defer func() {
+		time.Local = local
+	}()
+
+	path := filepath.Join(dir, "app.log")
+	recent := filepath.Join(dir, "app-"+time.Now().Add(-time.Hour).Format(backupTimeFormat)+".log")
+	if err := ioutil.WriteFile(recent, []byte("recent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := newRotateFile(path, RotateCfg{MaxSize: 1, MaxAge: 2 * time.Hour, LocalTime: true}, newMetrics())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// rotations in the same millisecond don't overwrite backups
+	f.mu.Lock()
+	for i := 0; i < 3; i++ {
+		if _, err := f.file.Write([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.mu.Unlock()
+	if err := f.mill(); err != nil {
+		t.Fatal(err)
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 5 {
+		t.Fatalf("expected 3 rotated, recent and current files, got %d", len(infos))
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Fatalf("recent backup in local time is removed: %v", err)
+	}
+}
+
+func TestRotateFilePeriods(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	f := &rotateFile{cfg: RotateCfg{Every: 24 * time.Hour, LocalTime: true}, loc: loc}
+	at := func(day, hour int) time.Time {
+		return time.Date(2026, time.October, day, hour, 30, 0, 0, loc)
+	}
+	// UTC midnight is at 03:00 of location
+	if !f.periodStart(at(19, 2)).Equal(f.periodStart(at(19, 4))) {
+		t.Error("file is rotated at UTC midnight")
+	}
+	if f.periodStart(at(19, 23)).Equal(f.periodStart(at(20, 0))) {
+		t.Error("file isn't rotated at midnight of location")
+	}
+	if start := f.periodStart(at(19, 23)); !start.Equal(time.Date(2026, time.October, 19, 0, 0, 0, 0, loc)) {
+		t.Errorf("period starts at %v", start)
+	}
+}
@@ -0,0 +1,426 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	SinkStdout   = "stdout"
+	SinkStderr   = "stderr"
+	SinkFile     = "file"
+	SinkSyslog   = "syslog"
+	SinkJournald = "journald"
+	SinkTCP      = "tcp"
+	SinkUDP      = "udp"
+
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+)
+
+var ErrUnknownSink = errors.New("unknown sink type")
+
+// SinkCfg is a setting of single logger output
+type SinkCfg struct {
+	// Type is one of stdout, stderr, file, syslog, journald, tcp or udp
+	Type string
+	// Path is a file path for file sink
+	Path string
+	// Network is one of unix, unixgram, udp, tcp or tls for syslog sink, local syslog is used if empty
+	Network string
+	// Address is a remote address for syslog, tcp or udp sinks, or a socket path for journald sink
+	Address string
+	// Tag is a syslog app name or journald identifier, process name is used if empty
+	Tag string
+	// Facility is a syslog facility name, e.g. local0, user is used if empty
+	Facility string
+	// TLS is a setting of tls connection for syslog sink
+	TLS TLSCfg
+	// Level is a minimum sink level, general logger level is used if not set
+	Level *Level
+	// Encoding is one of json, console or logfmt, depends on debug mode if empty, syslog and journald sinks have own format
+	Encoding  string
+	DebugTags []string
+	// Filter is an expression of entries written into sink, e.g. tag:payments AND level>=warning
+	Filter string
+	// Rotate is a setting of rotation for file sink
+	Rotate RotateCfg
+}
+
+// TLSCfg is a setting of tls client connection
+type TLSCfg struct {
+	// CAFile is a path to PEM encoded certificates of trusted authorities, system pool is used if empty
+	CAFile string
+	// CertFile and KeyFile are paths to PEM encoded client certificate and key
+	CertFile string
+	KeyFile  string
+	// ServerName is used to verify certificate of server, host of address is used if empty
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+func (c TLSCfg) config() (*tls.Config, error) {
+	tc := &tls.Config{ServerName: c.ServerName, InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CAFile != "" {
+		pem, e := ioutil.ReadFile(c.CAFile)
+		if e != nil {
+			return nil, e
+		}
+		tc.RootCAs = x509.NewCertPool()
+		if !tc.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", c.CAFile)
+		}
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, e := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if e != nil {
+			return nil, e
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
+
+// configuredSinks returns sinks from config, logger writes into stdout if sinks are not configured
+func configuredSinks(cfg *Config) []SinkCfg {
+	if len(cfg.Sinks) == 0 {
+		return []SinkCfg{{Type: SinkStdout}}
+	}
+	return cfg.Sinks
+}
+
+// sinkLevel returns minimum level of sink, the most verbose level of named loggers is used if not set
+func sinkLevel(cfg *Config, s SinkCfg) Level {
+	if s.Level != nil {
+		return *s.Level
+	}
+	return generalLevel(cfg)
+}
+
+// sinksLevel returns the most verbose of levels set by sinks explicitly, entries of such levels are passed to sinks
+// by any named logger
+func sinksLevel(cfg *Config) Level {
+	level := LevelEmergency
+	for _, s := range cfg.Sinks {
+		if s.Level != nil && *s.Level > level {
+			level = *s.Level
+		}
+	}
+	return level
+}
+
+// sinksLayout returns settings of sinks excluding levels, sinks are rebuilt on reload only if layout is changed
+func sinksLayout(cfg *Config) interface{} {
+	sinks := make([]SinkCfg, 0, len(cfg.Sinks))
+	for _, s := range configuredSinks(cfg) {
+		s.Level = nil
+		sinks = append(sinks, s)
+	}
+	return struct {
+		Debug           bool
+		MapTagsSplitSep string
+		Sinks           []SinkCfg
+		Async           AsyncCfg
+	}{cfg.Debug, cfg.MapTagsSplitSep, sinks, cfg.Async}
+}
+
+// sinks is a zap logger built from configured sinks
+type sinks struct {
+	logger  *zap.Logger
+	levels  []zap.AtomicLevel
+	layout  interface{}
+	closer  func()
+	reopens []func() error
+}
+
+// output keeps zap logger built from sinks, it's shared between instance and its children and swapped on reload
+type output struct {
+	logger atomic.Value
+	mu     sync.Mutex
+	sinks  *sinks
+}
+
+func newOutput(s *sinks) *output {
+	o := &output{}
+	o.swap(s)
+	return o
+}
+
+func (o *output) load() *zap.Logger {
+	return o.logger.Load().(*zap.Logger)
+}
+
+// swap replaces current logger and closes outputs of previous one
+func (o *output) swap(s *sinks) {
+	o.mu.Lock()
+	prev := o.sinks
+	o.sinks = s
+	o.logger.Store(s.logger)
+	o.mu.Unlock()
+	if prev != nil && prev.closer != nil {
+		prev.closer()
+	}
+}
+
+// update sets levels of current sinks, returns false if sinks should be rebuilt because of changed layout
+func (o *output) update(cfg *Config) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.sinks == nil || o.sinks.layout == nil || !reflect.DeepEqual(o.sinks.layout, sinksLayout(cfg)) {
+		return false
+	}
+	for i, s := range configuredSinks(cfg) {
+		o.sinks.levels[i].SetLevel(cfgLevelToZap(sinkLevel(cfg, s)))
+	}
+	return true
+}
+
+// reopen closes and opens files of current sinks, it's useful after rotation by external tools
+func (o *output) reopen() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var err error
+	for _, fn := range o.sinks.reopens {
+		if e := fn(); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// buildLogger returns zap logger writes into all configured sinks
+func buildLogger(cfg *Config, sh *shared) (*sinks, error) {
+	var (
+		configured = configuredSinks(cfg)
+		cores      = make([]zapcore.Core, 0, len(configured))
+		levels     = make([]zap.AtomicLevel, 0, len(configured))
+		closers    = make([]func(), 0, len(configured))
+		reopens    []func() error
+	)
+	closer := func() {
+		for _, fn := range closers {
+			fn()
+		}
+	}
+	for i, s := range configured {
+		level := zap.NewAtomicLevelAt(cfgLevelToZap(sinkLevel(cfg, s)))
+		core, fn, reopen, e := newSinkCore(cfg, s, level, sh.metrics)
+		if e != nil {
+			closer()
+			return nil, errors.WithMessage(e, fmt.Sprintf("%s: sink #%d %s", Prefix, i, s.Type))
+		}
+		// level of sink without own level depends on name of logger
+		if s.Level == nil {
+			core = &namedLevelCore{Core: core, shared: sh}
+		}
+		cores = append(cores, core)
+		levels = append(levels, level)
+		closers = append(closers, fn)
+		if reopen != nil {
+			reopens = append(reopens, reopen)
+		}
+	}
+	core := zapcore.NewTee(cores...)
+	options := []zap.Option{
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.AddCaller(),
+		zap.AddCallerSkip(2),
+	}
+	if cfg.Debug {
+		options = append(options, zap.Development(), zap.AddStacktrace(zapLevel(LevelWarning)))
+	} else {
+		options = append(options, zap.AddStacktrace(zapLevel(LevelEmergency)))
+	}
+	logger := zap.New(core, options...)
+	return &sinks{
+		logger: logger,
+		levels: levels,
+		layout: sinksLayout(cfg),
+		closer: func() {
+			_ = logger.Sync()
+			closer()
+		},
+		reopens: reopens,
+	}, nil
+}
+
+func newSinkEncoder(cfg *Config, s SinkCfg) (zapcore.Encoder, error) {
+	var encCfg zapcore.EncoderConfig
+	if cfg.Debug {
+		encCfg = zap.NewDevelopmentEncoderConfig()
+	} else {
+		encCfg = zap.NewProductionEncoderConfig()
+	}
+	encCfg.LevelKey = FieldLevel
+	encCfg.EncodeLevel = LevelEncoder
+	encCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	var enc zapcore.Encoder
+	switch s.Encoding {
+	case "":
+		if cfg.Debug {
+			enc = zapcore.NewConsoleEncoder(encCfg)
+		} else {
+			enc = zapcore.NewJSONEncoder(encCfg)
+		}
+	case EncodingJSON:
+		enc = zapcore.NewJSONEncoder(encCfg)
+	case EncodingConsole:
+		enc = zapcore.NewConsoleEncoder(encCfg)
+	case EncodingLogfmt:
+		enc = NewLogfmtEncoder(encCfg)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", s.Encoding)
+	}
+	return NewSeverityEncoder(enc), nil
+}
+
+// newSinkCore returns core of sink with its closer, file sinks also return func reopens file
+func newSinkCore(cfg *Config, s SinkCfg, enabler zapcore.LevelEnabler, m *metrics) (zapcore.Core, func(), func() error, error) {
+	var (
+		enc    zapcore.Encoder
+		ws     zapcore.WriteSyncer
+		closer = func() {}
+		reopen func() error
+		stream = true
+		e      error
+	)
+	// syslog and journald sinks have own format
+	if s.Type != SinkSyslog && s.Type != SinkJournald {
+		if enc, e = newSinkEncoder(cfg, s); e != nil {
+			return nil, nil, nil, e
+		}
+	}
+	switch s.Type {
+	case SinkStdout, SinkStderr:
+		if ws, closer, e = zap.Open(s.Type); e != nil {
+			return nil, nil, nil, e
+		}
+	case SinkFile:
+		rf, e := newRotateFile(s.Path, s.Rotate, m)
+		if e != nil {
+			return nil, nil, nil, e
+		}
+		ws, closer, reopen = rf, func() { _ = rf.Close() }, rf.Reopen
+	case SinkTCP, SinkUDP:
+		ns := &netSink{network: s.Type, address: s.Address}
+		if e := ns.dial(); e != nil {
+			return nil, nil, nil, e
+		}
+		ws, closer, stream = ns, func() { _ = ns.Close() }, isStream(ns.network)
+	case SinkSyslog:
+		ns, e := newSyslogSink(s)
+		if e != nil {
+			return nil, nil, nil, e
+		}
+		if enc, e = NewSyslogEncoder(s.Tag, s.Facility, isStream(ns.network)); e != nil {
+			_ = ns.Close()
+			return nil, nil, nil, e
+		}
+		ws, closer, stream = ns, func() { _ = ns.Close() }, isStream(ns.network)
+	case SinkJournald:
+		js, e := newJournaldSink(s.Address)
+		if e != nil {
+			return nil, nil, nil, e
+		}
+		enc, ws, closer, stream = NewJournaldEncoder(s.Tag), js, func() { _ = js.Close() }, false
+	default:
+		return nil, nil, nil, ErrUnknownSink
+	}
+	var core zapcore.Core
+	if cfg.Async.Enabled {
+		aw, e := newAsyncWriter(ws, cfg.Async, stream, m, s.Type)
+		if e != nil {
+			closer()
+			return nil, nil, nil, e
+		}
+		core = &asyncCore{LevelEnabler: enabler, enc: enc, writer: aw}
+		closeOutput := closer
+		closer = func() {
+			_ = aw.Close()
+			closeOutput()
+		}
+	} else {
+		core = zapcore.NewCore(enc, ws, enabler)
+	}
+	core = &countingCore{Core: core, metrics: m, sink: s.Type}
+	if len(s.DebugTags) > 0 || s.Filter != "" {
+		filter, e := newFilter(s.DebugTags, cfg.MapTagsSplitSep, s.Filter)
+		if e != nil {
+			closer()
+			return nil, nil, nil, e
+		}
+		core = &filterCore{Core: core, filter: filter}
+	}
+	return core, closer, reopen, nil
+}
+
+// netSink is zapcore.WriteSyncer sends entries into socket, connection is reestablished on write error
+type netSink struct {
+	mu      sync.Mutex
+	network string
+	address string
+	tls     *tls.Config
+	conn    net.Conn
+}
+
+func (s *netSink) dial() error {
+	var (
+		conn net.Conn
+		e    error
+	)
+	if s.tls != nil {
+		conn, e = tls.Dial(s.network, s.address, s.tls)
+	} else {
+		conn, e = net.Dial(s.network, s.address)
+	}
+	if e != nil {
+		return e
+	}
+	s.conn = conn
+	return nil
+}
+
+// Write
+func (s *netSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		if n, e := s.conn.Write(p); e == nil {
+			return n, nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	if e := s.dial(); e != nil {
+		return 0, e
+	}
+	return s.conn.Write(p)
+}
+
+// Sync
+func (s *netSink) Sync() error {
+	return nil
+}
+
+// Close
+func (s *netSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	e := s.conn.Close()
+	s.conn = nil
+	return e
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	journaldSocket = "/run/systemd/journal/socket"
+	// journaldMaxField is a maximum length of journal field name
+	journaldMaxField = 64
+)
+
+// journaldSink sends entries into journald socket, entries exceeding datagram size are passed via file descriptor
+type journaldSink struct {
+	*netSink
+}
+
+func newJournaldSink(path string) (*journaldSink, error) {
+	if path == "" {
+		path = journaldSocket
+	}
+	ns := &netSink{network: "unixgram", address: path}
+	if e := ns.dial(); e != nil {
+		return nil, e
+	}
+	return &journaldSink{netSink: ns}, nil
+}
+
+// Write
+func (s *journaldSink) Write(p []byte) (int, error) {
+	n, e := s.netSink.Write(p)
+	if e != nil && journaldTooLarge(e) {
+		if e = s.sendFile(p); e != nil {
+			return 0, e
+		}
+		return len(p), nil
+	}
+	return n, e
+}
+
+// journaldEncoder is zapcore.Encoder writes entries in journald native protocol, fields are written as journal fields
+type journaldEncoder struct {
+	*zapcore.MapObjectEncoder
+	identifier string
+}
+
+// NewJournaldEncoder returns zapcore.Encoder for journald native protocol
+func NewJournaldEncoder(identifier string) zapcore.Encoder {
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	return &journaldEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), identifier: identifier}
+}
+
+// Clone copies the encoder, ensuring that adding fields to the copy doesn't affect the original.
+func (e *journaldEncoder) Clone() zapcore.Encoder {
+	clone := &journaldEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), identifier: e.identifier}
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return clone
+}
+
+// EncodeEntry encodes an entry and fields, along with any accumulated context, into a byte buffer and returns it.
+func (e *journaldEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.Clone().(*journaldEncoder)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	buf := syslogPool.Get()
+	writeJournalField(buf, "PRIORITY", strconv.Itoa(int(levelFromZap(ent.Level))))
+	writeJournalField(buf, "SYSLOG_IDENTIFIER", e.identifier)
+	writeJournalField(buf, "MESSAGE", ent.Message)
+	if ent.Caller.Defined {
+		writeJournalField(buf, "CODE_FILE", ent.Caller.File)
+		writeJournalField(buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+	}
+	if ent.Stack != "" {
+		writeJournalField(buf, "STACKTRACE", ent.Stack)
+	}
+	if tags, ok := final.Fields[fieldTags].([]interface{}); ok {
+		for _, tag := range tags {
+			writeJournalField(buf, "TAG", fieldString(tag))
+		}
+	}
+	keys := make([]string, 0, len(final.Fields))
+	for k := range final.Fields {
+		if k != fieldTags {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeJournalField(buf, journalFieldName(k), fieldString(final.Fields[k]))
+	}
+	return buf, nil
+}
+
+// writeJournalField writes field as NAME=value line, multiline values are written with binary length
+func writeJournalField(buf *buffer.Buffer, name, value string) {
+	buf.AppendString(name)
+	if !strings.ContainsRune(value, '\n') {
+		buf.AppendByte('=')
+		buf.AppendString(value)
+		buf.AppendByte('\n')
+		return
+	}
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.AppendByte('\n')
+	_, _ = buf.Write(size[:])
+	buf.AppendString(value)
+	buf.AppendByte('\n')
+}
+
+// journalFieldName returns valid journal field name, it consists of upper case letters, digits and underscores
+// and starts with a letter
+func journalFieldName(key string) string {
+	b := []byte(strings.ToUpper(key))
+	for i, c := range b {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	name := strings.TrimLeft(string(b), "_")
+	if name == "" || name[0] <= '9' {
+		name = "F_" + name
+	}
+	if len(name) > journaldMaxField {
+		name = name[:journaldMaxField]
+	}
+	return name
+}
@@ -0,0 +1,18 @@
+//go:build windows || nacl || plan9
+// +build windows nacl plan9
+
+package logger
+
+import (
+	"github.com/pkg/errors"
+)
+
+var ErrJournaldNotSupported = errors.New("passing of large journald entries is not supported on this platform")
+
+func journaldTooLarge(e error) bool {
+	return false
+}
+
+func (s *journaldSink) sendFile(p []byte) error {
+	return ErrJournaldNotSupported
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournaldSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "journal.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log := newSinkLogger(ctx, SinkCfg{Type: SinkJournald, Address: path, Tag: "billing"})
+	log.WithTags(Tags{"payments"}).Critical("declined", WithFields(Fields{"order-id": 42, "reason": "line 1\nline 2"}))
+
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := string(buf[:n])
+	for _, line := range []string{"PRIORITY=2\n", "SYSLOG_IDENTIFIER=billing\n", "MESSAGE=declined\n", "TAG=payments\n", "ORDER_ID=42\n"} {
+		if !strings.Contains(entry, line) {
+			t.Errorf("entry doesn't contain %q: %q", line, entry)
+		}
+	}
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len("line 1\nline 2")))
+	if !strings.Contains(entry, "REASON\n"+string(size[:])+"line 1\nline 2\n") {
+		t.Errorf("multiline field is not encoded: %q", entry)
+	}
+}
@@ -0,0 +1,52 @@
+//go:build !windows && !nacl && !plan9
+// +build !windows,!nacl,!plan9
+
+package logger
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"syscall"
+)
+
+// journaldTooLarge returns true if entry is rejected because of datagram size
+func journaldTooLarge(e error) bool {
+	if oe, ok := e.(*net.OpError); ok {
+		e = oe.Err
+	}
+	if se, ok := e.(*os.SyscallError); ok {
+		e = se.Err
+	}
+	return e == syscall.EMSGSIZE || e == syscall.ENOBUFS
+}
+
+// sendFile passes entry to journald via descriptor of unlinked temporary file
+func (s *journaldSink) sendFile(p []byte) error {
+	f, e := ioutil.TempFile("/dev/shm", "journal")
+	if e != nil {
+		if f, e = ioutil.TempFile("", "journal"); e != nil {
+			return e
+		}
+	}
+	defer f.Close()
+	if e = os.Remove(f.Name()); e != nil {
+		return e
+	}
+	if _, e = f.Write(p); e != nil {
+		return e
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		if e = s.dial(); e != nil {
+			return e
+		}
+	}
+	conn, ok := s.conn.(*net.UnixConn)
+	if !ok {
+		return syscall.EINVAL
+	}
+	_, _, e = conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), nil)
+	return e
+}
@@ -0,0 +1,227 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	NetworkTLS = "tls"
+	// SyslogSDFields and SyslogSDTags are ids of structured data elements built from fields and tags,
+	// 32473 is the enterprise number reserved for documentation by RFC5612
+	SyslogSDFields   = "fields@32473"
+	SyslogSDTags     = "tags@32473"
+	syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+	syslogNilValue   = "-"
+)
+
+var (
+	ErrUnknownFacility = errors.New("unknown syslog facility")
+	ErrNoLocalSyslog   = errors.New("local syslog socket is not found")
+
+	syslogSockets    = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+	syslogFacilities = map[string]int{
+		"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+		"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+		"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+		"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+	}
+	syslogPool = buffer.NewPool()
+)
+
+// newSyslogSink returns connection to syslog daemon, local unix socket is used if network is empty
+func newSyslogSink(s SinkCfg) (*netSink, error) {
+	ns := &netSink{network: s.Network, address: s.Address}
+	switch s.Network {
+	case "":
+		for _, path := range syslogSockets {
+			for _, network := range []string{"unixgram", "unix"} {
+				ns = &netSink{network: network, address: path}
+				if e := ns.dial(); e == nil {
+					return ns, nil
+				}
+			}
+		}
+		return nil, ErrNoLocalSyslog
+	case NetworkTLS:
+		tc, e := s.TLS.config()
+		if e != nil {
+			return nil, e
+		}
+		ns = &netSink{network: "tcp", address: s.Address, tls: tc}
+	}
+	if e := ns.dial(); e != nil {
+		return nil, e
+	}
+	return ns, nil
+}
+
+// isStream returns true for connection oriented networks, messages are framed by octet counting there
+func isStream(network string) bool {
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		return true
+	}
+	return false
+}
+
+// syslogEncoder is zapcore.Encoder writes entries as RFC5424 messages, fields and tags are written as structured data
+type syslogEncoder struct {
+	*zapcore.MapObjectEncoder
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	stream   bool
+}
+
+// NewSyslogEncoder returns zapcore.Encoder for RFC5424 format, stream messages are prefixed with length by RFC6587
+func NewSyslogEncoder(appName, facility string, stream bool) (zapcore.Encoder, error) {
+	code := syslogFacilities["user"]
+	if facility != "" {
+		var ok bool
+		if code, ok = syslogFacilities[facility]; !ok {
+			return nil, errors.WithMessage(ErrUnknownFacility, facility)
+		}
+	}
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	hostname, _ := os.Hostname()
+	return &syslogEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		facility:         code,
+		hostname:         syslogHeader(hostname, 255),
+		appName:          syslogHeader(appName, 48),
+		procID:           strconv.Itoa(os.Getpid()),
+		stream:           stream,
+	}, nil
+}
+
+// Clone copies the encoder, ensuring that adding fields to the copy doesn't affect the original.
+func (e *syslogEncoder) Clone() zapcore.Encoder {
+	clone := *e
+	clone.MapObjectEncoder = zapcore.NewMapObjectEncoder()
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return &clone
+}
+
+// EncodeEntry encodes an entry and fields, along with any accumulated context, into a byte buffer and returns it.
+func (e *syslogEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.Clone().(*syslogEncoder)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	buf := syslogPool.Get()
+	buf.AppendByte('<')
+	buf.AppendInt(int64(e.facility*8 + int(levelFromZap(ent.Level))))
+	buf.AppendString(">1 ")
+	buf.AppendString(ent.Time.Format(syslogTimeFormat))
+	buf.AppendByte(' ')
+	buf.AppendString(e.hostname)
+	buf.AppendByte(' ')
+	buf.AppendString(e.appName)
+	buf.AppendByte(' ')
+	buf.AppendString(e.procID)
+	buf.AppendString(" " + syslogNilValue + " ")
+	writeSyslogSD(buf, final.Fields, ent.Caller)
+	buf.AppendByte(' ')
+	buf.AppendString(ent.Message)
+	if ent.Stack != "" {
+		buf.AppendByte('\n')
+		buf.AppendString(ent.Stack)
+	}
+	if !e.stream {
+		return buf, nil
+	}
+	framed := syslogPool.Get()
+	framed.AppendInt(int64(buf.Len()))
+	framed.AppendByte(' ')
+	_, _ = framed.Write(buf.Bytes())
+	buf.Free()
+	return framed, nil
+}
+
+// writeSyslogSD writes fields and tags as structured data elements
+func writeSyslogSD(buf *buffer.Buffer, fields map[string]interface{}, caller zapcore.EntryCaller) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if k != fieldTags {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	tags, _ := fields[fieldTags].([]interface{})
+	if len(keys) == 0 && len(tags) == 0 && !caller.Defined {
+		buf.AppendString(syslogNilValue)
+		return
+	}
+	if len(keys) > 0 || caller.Defined {
+		buf.AppendString("[" + SyslogSDFields)
+		if caller.Defined {
+			writeSyslogParam(buf, "caller", caller.TrimmedPath())
+		}
+		for _, k := range keys {
+			writeSyslogParam(buf, k, fieldString(fields[k]))
+		}
+		buf.AppendByte(']')
+	}
+	if len(tags) > 0 {
+		buf.AppendString("[" + SyslogSDTags)
+		for _, tag := range tags {
+			writeSyslogParam(buf, "tag", fieldString(tag))
+		}
+		buf.AppendByte(']')
+	}
+}
+
+func writeSyslogParam(buf *buffer.Buffer, name, value string) {
+	buf.AppendByte(' ')
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	if name == "" {
+		name = "_"
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		buf.AppendByte(c)
+	}
+	buf.AppendString(`="`)
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '"', '\\', ']':
+			buf.AppendByte('\\')
+		}
+		buf.AppendByte(value[i])
+	}
+	buf.AppendByte('"')
+}
+
+// syslogHeader returns header field of printable ascii characters limited by length
+func syslogHeader(s string, max int) string {
+	if s == "" {
+		return syslogNilValue
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	b := []byte(s)
+	for i, c := range b {
+		if c <= ' ' || c > '~' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
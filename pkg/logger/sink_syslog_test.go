@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+)
+
+func newSinkLogger(ctx context.Context, sink SinkCfg) *Zap {
+	return NewZap(ctx, &Config{
+		Level:                 LevelDebug,
+		MapTagsSplitSep:       ":",
+		DisableRedirectStdLog: true,
+		Sampling:              SamplingCfg{Disabled: true},
+		Sinks:                 []SinkCfg{sink},
+		invoker:               invoker.NewInvoker(),
+	})
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log := newSinkLogger(ctx, SinkCfg{Type: SinkSyslog, Network: "udp", Address: pc.LocalAddr().String(), Tag: "billing", Facility: "local0"})
+	log.WithTags(Tags{"payments"}).Notice("paid", WithFields(Fields{"user": `john "doe"]`}))
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// local0 * 8 + notice
+	if !strings.HasPrefix(msg, "<133>1 ") || !strings.Contains(msg, " billing ") {
+		t.Errorf("unexpected header: %s", msg)
+	}
+	if !strings.Contains(msg, `user="john \"doe\"\]"]`) || !strings.HasSuffix(msg, `[tags@32473 tag="payments"] paid`) {
+		t.Errorf("unexpected structured data: %s", msg)
+	}
+}
+
+func TestSyslogSinkTCPReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, e := l.Accept()
+			if e != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+	read := func(conn net.Conn) string {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		size, e := r.ReadString(' ')
+		if e != nil {
+			t.Fatal(e)
+		}
+		n, e := strconv.Atoi(strings.TrimSpace(size))
+		if e != nil {
+			t.Fatal(e)
+		}
+		b := make([]byte, n)
+		if _, e = io.ReadFull(r, b); e != nil {
+			t.Fatal(e)
+		}
+		return string(b)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	log := newSinkLogger(ctx, SinkCfg{Type: SinkSyslog, Network: "tcp", Address: l.Addr().String()})
+	log.Error("first")
+	first := <-conns
+	if msg := read(first); !strings.HasPrefix(msg, "<11>1 ") || !strings.HasSuffix(msg, " first") {
+		t.Errorf("unexpected message: %s", msg)
+	}
+	_ = first.Close()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		log.Error("second")
+		select {
+		case second := <-conns:
+			if msg := read(second); !strings.HasSuffix(msg, " second") {
+				t.Errorf("unexpected message after reconnect: %s", msg)
+			}
+			_ = second.Close()
+			return
+		case <-deadline:
+			t.Fatal("sink is not reconnected")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
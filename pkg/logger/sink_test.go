@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+)
+
+func TestSinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	warning := LevelWarning
+	cfg := &Config{
+		Level:                 LevelInfo,
+		MapTagsSplitSep:       ":",
+		DisableRedirectStdLog: true,
+		invoker:               invoker.NewInvoker(),
+		Sinks: []SinkCfg{
+			{Type: SinkFile, Path: filepath.Join(dir, "all.log"), Encoding: EncodingLogfmt},
+			{Type: SinkFile, Path: filepath.Join(dir, "warn.log"), Level: &warning},
+			{Type: SinkFile, Path: filepath.Join(dir, "payments.log"), DebugTags: []string{"payments"}},
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	log := NewZap(ctx, cfg)
+	log.Info("started", WithFields(Fields{"user": "john doe"}))
+	log.WithTags(Tags{"payments"}).Warning("declined")
+	log.Debug("skipped")
+	if e := log.shared.out.load().Sync(); e != nil {
+		t.Fatal(e)
+	}
+
+	read := func(name string) []string {
+		b, e := ioutil.ReadFile(filepath.Join(dir, name))
+		if e != nil {
+			t.Fatal(e)
+		}
+		return strings.Split(strings.TrimSpace(string(b)), "\n")
+	}
+	all := read("all.log")
+	if len(all) != 2 || !strings.Contains(all[0], `msg=started`) || !strings.Contains(all[0], `user="john doe"`) {
+		t.Errorf("unexpected logfmt output: %q", all)
+	}
+	if warn := read("warn.log"); len(warn) != 1 || !strings.Contains(warn[0], `"msg":"declined"`) {
+		t.Errorf("unexpected warning sink output: %q", warn)
+	}
+	if payments := read("payments.log"); len(payments) != 1 || !strings.Contains(payments[0], `"tags":["payments"]`) {
+		t.Errorf("unexpected filtered sink output: %q", payments)
+	}
+}
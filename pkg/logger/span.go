@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	SpanFieldEvent   = "event"
+	SpanFieldLevel   = "level"
+	SpanFieldMessage = "message"
+	SpanFieldTags    = "tags"
+)
+
+// logSpan writes log record into the span held by context, levels at or above warning mark the span as failed
+func logSpan(ctx context.Context, level Level, message string, tags []string, zfields []zapcore.Field) {
+	if ctx == nil {
+		return
+	}
+	span := opentracing.SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	// fields of call follow fields of logger, so the last field of key wins and overrides fields of span record
+	values := fieldsMap(zfields)
+	fields := make([]otlog.Field, 0, 4+len(values))
+	builtin := func(key, value string) {
+		if _, ok := values[key]; !ok {
+			fields = append(fields, otlog.String(key, value))
+		}
+	}
+	builtin(SpanFieldEvent, level.String())
+	builtin(SpanFieldLevel, level.String())
+	builtin(SpanFieldMessage, message)
+	if len(tags) > 0 {
+		builtin(SpanFieldTags, strings.Join(tags, ","))
+	}
+	added := make(map[string]struct{}, len(values))
+	for _, f := range zfields {
+		if _, ok := added[f.Key]; ok {
+			continue
+		}
+		if v, ok := values[f.Key]; ok {
+			fields = append(fields, spanField(f.Key, v))
+			added[f.Key] = struct{}{}
+		}
+	}
+	if level <= LevelWarning {
+		ext.Error.Set(span, true)
+	}
+	span.LogFields(fields...)
+}
+
+// spanField converts value to the typed opentracing log field
+func spanField(key string, val interface{}) otlog.Field {
+	switch v := val.(type) {
+	case string:
+		return otlog.String(key, v)
+	case bool:
+		return otlog.Bool(key, v)
+	case int:
+		return otlog.Int(key, v)
+	case int32:
+		return otlog.Int32(key, v)
+	case int64:
+		return otlog.Int64(key, v)
+	case uint32:
+		return otlog.Uint32(key, v)
+	case uint64:
+		return otlog.Uint64(key, v)
+	case float32:
+		return otlog.Float32(key, v)
+	case float64:
+		return otlog.Float64(key, v)
+	case error:
+		return otlog.String(key, v.Error())
+	case fmt.Stringer:
+		return otlog.String(key, v.String())
+	}
+	return otlog.Object(key, val)
+}
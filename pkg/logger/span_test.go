@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"go.uber.org/zap"
+)
+
+func TestSpanLogs(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("test")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	cfg := &Config{SpanLogs: true, Level: LevelDebug, DisableRedirectStdLog: true}
+	log := WrapLogger(context.Background(), zap.NewNop(), cfg).WithTags(Tags{"payments"}).WithFields(Fields{"user_id": 42})
+
+	log.Info("skipped, no context")
+	log.Info("charged %v", Args("card"), WithContext(ctx))
+	log.Warning("declined", WithContext(ctx), WithFields(Fields{"reason": "limit"}))
+	span.Finish()
+
+	records := tracer.FinishedSpans()[0].Logs()
+	if len(records) != 2 {
+		t.Fatalf("expected 2 span log records, got %d", len(records))
+	}
+	kv := map[string]string{}
+	for _, f := range records[0].Fields {
+		kv[f.Key] = f.ValueString
+	}
+	if kv[SpanFieldMessage] != "charged card" || kv[SpanFieldLevel] != "info" || kv[SpanFieldTags] != "payments" || kv["user_id"] != "42" {
+		t.Errorf("unexpected span log fields: %v", kv)
+	}
+	if v := tracer.FinishedSpans()[0].Tag("error"); v != true {
+		t.Errorf("expected error tag to be set, got %v", v)
+	}
+}
+
+func TestSpanLogsPrecedence(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("test")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	cfg := &Config{SpanLogs: true, Level: LevelDebug, DisableRedirectStdLog: true}
+	log := WrapLogger(context.Background(), zap.NewNop(), cfg).WithFields(Fields{"user_id": 42, "order_id": 1})
+	log.Info("charged", WithContext(ctx), WithFields(Fields{"user_id": 7, SpanFieldMessage: "call-site"}), String("order_id", "2"))
+	span.Finish()
+
+	kv := map[string][]string{}
+	for _, f := range tracer.FinishedSpans()[0].Logs()[0].Fields {
+		kv[f.Key] = append(kv[f.Key], f.ValueString)
+	}
+	for key, expected := range map[string]string{"user_id": "7", "order_id": "2", SpanFieldMessage: "call-site", SpanFieldLevel: "info"} {
+		if v := kv[key]; len(v) != 1 || v[0] != expected {
+			t.Errorf("field %s is %v, expected only call-site value %s", key, v, expected)
+		}
+	}
+}
+
+func TestSpanLogsOfLoggerContext(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("test")
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	cfg := &Config{SpanLogs: true, Level: LevelDebug, DisableRedirectStdLog: true}
+	log := WrapLogger(ctx, zap.NewNop(), cfg).WithTags(Tags{"payments"})
+	log.Error("declined")
+	span.Finish()
+
+	records := tracer.FinishedSpans()[0].Logs()
+	if len(records) != 1 {
+		t.Fatalf("expected 1 span log record, got %d", len(records))
+	}
+	kv := map[string]string{}
+	for _, f := range records[0].Fields {
+		kv[f.Key] = f.ValueString
+	}
+	if kv[SpanFieldMessage] != "declined" || kv[SpanFieldLevel] != "error" || kv[SpanFieldTags] != "payments" {
+		t.Errorf("unexpected span log fields: %v", kv)
+	}
+}
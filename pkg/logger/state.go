@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// settings is immutable snapshot of config with derived values, it's replaced as a whole on reload
+type settings struct {
+	Config
+	// sinksLevel is the most verbose level set by sinks explicitly, it's used for pre-filtering log calls
+	// along with level of named logger
+	sinksLevel Level
+	levels     map[string]Level
+	filter     *filterExpr
+	limiter    *limiter
+	masker     *masker
+	// err describes invalid settings, they are skipped
+	err error
+}
+
+// newSettings returns settings of config, limiter of previous settings is kept with updated rules
+func newSettings(cfg *Config, l *limiter) *settings {
+	s := &settings{Config: cloneConfig(cfg)}
+	s.sinksLevel = sinksLevel(&s.Config)
+	s.levels = nameLevels(s.Levels)
+	if l == nil {
+		l = newLimiter(&s.Config)
+	} else {
+		l.update(&s.Config)
+	}
+	s.limiter = l
+	var errs []string
+	filter, e := newFilter(s.DebugTags, s.MapTagsSplitSep, s.Filter)
+	if e != nil {
+		errs = append(errs, e.Error())
+		filter = newTagFilter(s.DebugTags, s.MapTagsSplitSep)
+	}
+	s.filter = filter
+	masker, e := newMasker(s.Mask)
+	if e != nil {
+		errs = append(errs, e.Error())
+	}
+	s.masker = masker
+	if len(errs) > 0 {
+		s.err = errors.New(strings.Join(errs, "; "))
+	}
+	return s
+}
+
+// shared is a state shared between instance and its children
+type shared struct {
+	settings atomic.Value
+	out      *output
+	metrics  *metrics
+}
+
+func newShared(cfg *Config, out *output, m *metrics) *shared {
+	s := &shared{out: out, metrics: m}
+	_ = s.store(cfg)
+	return s
+}
+
+func (s *shared) load() *settings {
+	return s.settings.Load().(*settings)
+}
+
+// store replaces settings, returns error describing invalid settings
+func (s *shared) store(cfg *Config) error {
+	var l *limiter
+	if prev, ok := s.settings.Load().(*settings); ok {
+		l = prev.limiter
+	}
+	st := newSettings(cfg, l)
+	s.settings.Store(st)
+	return st.err
+}
+
+// cloneConfig returns deep copy of config, reloading config decodes values into the same slices
+func cloneConfig(cfg *Config) Config {
+	c := *cfg
+	c.DebugTags = cloneStrings(cfg.DebugTags)
+	if cfg.Levels != nil {
+		c.Levels = make(map[string]Level, len(cfg.Levels))
+		for name, l := range cfg.Levels {
+			c.Levels[name] = l
+		}
+	}
+	if cfg.Sinks != nil {
+		c.Sinks = make([]SinkCfg, len(cfg.Sinks))
+		for i, s := range cfg.Sinks {
+			if s.Level != nil {
+				level := *s.Level
+				s.Level = &level
+			}
+			s.DebugTags = cloneStrings(s.DebugTags)
+			c.Sinks[i] = s
+		}
+	}
+	c.Mask.Fields = cloneStrings(cfg.Mask.Fields)
+	c.Mask.Patterns = cloneStrings(cfg.Mask.Patterns)
+	c.Mask.Builtin = cloneStrings(cfg.Mask.Builtin)
+	if cfg.Sampling.Levels != nil {
+		c.Sampling.Levels = make([]SamplingLevelCfg, len(cfg.Sampling.Levels))
+		copy(c.Sampling.Levels, cfg.Sampling.Levels)
+	}
+	return c
+}
+
+func cloneStrings(s []string) []string {
+	if s == nil {
+		return nil
+	}
+	c := make([]string, len(s))
+	copy(c, s)
+	return c
+}
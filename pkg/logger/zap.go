@@ -2,20 +2,25 @@ package logger
 
 import (
 	"context"
+	"github.com/uber-go/tally"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"log"
-	"strings"
+	"os"
+	"sync/atomic"
+	"time"
 )
 
 // Zap is uber/zap logger implemented of Logger interface
 type Zap struct {
 	ctx     context.Context
-	cfg     *Config
-	logger  *zap.SugaredLogger
+	shared  *shared
 	fields  map[string]interface{}
+	zfields []zapcore.Field
 	tags    []string
-	tagsMap []string
+	name    string
+	// named keeps *namedLogger built for the current output
+	named atomic.Value
 }
 
 // Printf is like fmt.Printf, push to log entry with debug level
@@ -25,7 +30,12 @@ func (z *Zap) Printf(format string, a ...interface{}) {
 
 // Verbose should return true when verbose logging output is wanted
 func (z *Zap) Verbose() bool {
-	return z.cfg.Verbose
+	return z.shared.load().Verbose
+}
+
+// Enabled returns false if entries of level are dropped by levels of sinks and named logger
+func (z *Zap) Enabled(level Level) bool {
+	return z.shared.load().enabled(z.name, level)
 }
 
 // Emergency push to log entry with emergency level & throw panic
@@ -74,129 +84,96 @@ func (z *Zap) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// Sync flushes buffered entries of all sinks
+func (z *Zap) Sync() error {
+	return z.shared.out.load().Sync()
+}
+
 // Log push to log with specified level
 func (z *Zap) Log(level Level, format string, o ...Option) {
-	opts := &opts{}
+	opts := acquireOpts()
+	defer releaseOpts(opts)
 	for _, option := range o {
 		_ = option(opts)
 	}
-	if !opts.ignoreLevelFilter && level > z.cfg.Level {
+	st := z.shared.load()
+	if !opts.ignoreLevelFilter && !st.enabled(z.name, level) {
 		return
 	}
-	var (
-		wargs = []interface{}{"level", level.String()}
-		tags  []string
-	)
-	// tags
-	if len(z.tags) > 0 {
-		tags = make([]string, len(z.tags))
-		copy(tags, z.tags)
-	}
+	tags := z.tags
 	if len(opts.tags) > 0 {
-		tags = append(tags, opts.tags...)
+		tags = make([]string, 0, len(z.tags)+len(opts.tags))
+		tags = append(append(tags, z.tags...), opts.tags...)
 	}
+	fields := opts.buf[:0]
 	if len(tags) > 0 {
-		wargs = append(wargs, "tags", tags)
+		fields = append(fields, zap.Strings(fieldTags, tags))
 	}
 	// fields
-	for k, v := range z.fields {
-		wargs = append(wargs, k, v)
-	}
-	for k, v := range opts.fields {
-		wargs = append(wargs, k, v)
-	}
-	if len(opts.wargs) > 0 {
-		wargs = append(wargs, opts.wargs...)
-		if len(opts.wargs)%2 != 0 {
-			wargs = append(wargs, "%!EXTRA")
-		}
-	}
+	kvFrom := len(fields)
+	fields = append(fields, z.zfields...)
+	fields = mapFields(fields, opts.fields)
+	fields = pairFields(fields, opts.wargs)
+	fields = append(fields, opts.zfields...)
+	kvTo := len(fields)
 	if opts.stack != nil {
-		wargs = append(wargs, zap.Stack(*opts.stack))
-	}
-	if opts.pfields != nil {
-		for key, val := range opts.pfields {
-			wargs = append(wargs, zap.Any(key, val))
-		}
+		fields = append(fields, zap.Stack(*opts.stack))
 	}
-	var logger = z.logger
-	if len(wargs) > 0 {
-		logger = logger.With(wargs...)
+	fields = mapFields(fields, opts.pfields)
+	opts.buf = fields
+	entry := &opts.entry
+	*entry = filterEntry{
+		level:  level,
+		format: format,
+		args:   opts.args,
+		masker: st.masker,
+		tags:   tags,
+		fields: fields,
 	}
-	if !z.pass(level, tags, wargs) {
+	if !st.filter.match(entry) {
 		return
 	}
-	if len(opts.args) == 0 {
-		var fn func(args ...interface{})
-		switch level {
-		default:
-			fn = logger.Debug
-		case LevelInfo, LevelNotice:
-			fn = logger.Info
-		case LevelWarning:
-			fn = logger.Warn
-		case LevelError, LevelCritical, LevelAlert:
-			fn = logger.Error
-		case LevelEmergency:
-			fn = logger.Panic
+	if !opts.unlimited {
+		if reason := st.limiter.allow(level, format, time.Now()); reason != "" {
+			z.shared.metrics.inc(MetricDropped, map[string]string{MetricTagReason: reason, MetricTagLevel: level.String()})
+			return
 		}
-		fn(format)
-	} else {
-		var fn func(format string, args ...interface{})
-		switch level {
-		default:
-			fn = logger.Debugf
-		case LevelInfo, LevelNotice:
-			fn = logger.Infof
-		case LevelWarning:
-			fn = logger.Warnf
-		case LevelError, LevelCritical, LevelAlert:
-			fn = logger.Errorf
-		case LevelEmergency:
-			fn = logger.Panicf
+	}
+	message := st.masker.text(entry.msg())
+	st.masker.fields(fields)
+	if st.SpanLogs {
+		// context of call is preferred to context of logger
+		ctx := opts.ctx
+		if ctx == nil {
+			ctx = z.ctx
 		}
-		fn(format, opts.args...)
+		logSpan(ctx, level, message, tags, fields[kvFrom:kvTo])
+	}
+	if ce := z.logger().Check(zapLevel(level), message); ce != nil {
+		ce.Write(fields...)
+	}
+	if level == LevelEmergency {
+		panic(message)
 	}
 }
 
-func (z *Zap) pass(level Level, tags []string, wargs []interface{}) bool {
-	var stop int8
-	if len(z.cfg.DebugTags) > 0 {
-		for _, tiv := range tags {
-			for _, requiredTag := range z.cfg.DebugTags {
-				if tiv == requiredTag {
-					return true
-				}
-			}
-		}
-		stop |= 1
+// logger returns zap logger of output with name of instance
+func (z *Zap) logger() *zap.Logger {
+	base := z.shared.out.load()
+	if z.name == "" {
+		return base
 	}
-	if len(z.tagsMap) > 0 {
-		var k interface{}
-		for i, v := range wargs {
-			if i%2 == 0 {
-				k = v
-				continue
-			}
-			if kk, ok := k.(string); ok {
-				if vv, ok := v.(string); ok {
-					var mk string
-					for mi, mv := range z.tagsMap {
-						if mi%2 == 0 {
-							mk = mv
-							continue
-						}
-						if kk == mk && vv == mv {
-							return true
-						}
-					}
-
-				}
-			}
-		}
-		stop |= 2
+	if n, ok := z.named.Load().(*namedLogger); ok && n.base == base {
+		return n.logger
 	}
-	return stop == 0
+	n := &namedLogger{base: base, logger: base.Named(z.name)}
+	z.named.Store(n)
+	return n.logger
+}
+
+// SetMetric sets scope for own metrics of logger, e.g. count of write errors
+func (z *Zap) SetMetric(scope tally.Scope) {
+	z.shared.metrics.set(scope)
 }
 
 // WithFields create new instance with fields
@@ -213,6 +190,14 @@ func (z *Zap) WithTags(tags Tags) Logger {
 	return nz
 }
 
+// Named create new instance with name joined to the current one, e.g. billing.stripe
+func (z *Zap) Named(name string) Logger {
+	nz := &Zap{}
+	copyZap(nz, z, nil, nil)
+	nz.name = joinName(z.name, name)
+	return nz
+}
+
 func copyZap(dst, src *Zap, tags []string, fields map[string]interface{}) {
 	// tags
 	cTags := make([]string, len(src.tags))
@@ -230,102 +215,114 @@ func copyZap(dst, src *Zap, tags []string, fields map[string]interface{}) {
 	for k, v := range fields {
 		dst.fields[k] = v
 	}
-	dst.logger = src.logger
-	dst.ctx = src.ctx
-	dst.cfg = src.cfg
-	dst.tagsMap = src.tagsMap
-}
-
-func parseTagsMap(cfg *Config) []string {
-	var tagsMap []string
-	if len(cfg.DebugTags) > 0 {
-		for _, requiredTag := range cfg.DebugTags {
-			rt := strings.Split(requiredTag, cfg.MapTagsSplitSep)
-			if len(rt) == 2 {
-				tagsMap = append(tagsMap, rt...)
-			}
-		}
+	// fields are converted once per instance
+	if fields != nil {
+		dst.zfields = mapFields(nil, dst.fields)
+	} else {
+		dst.zfields = src.zfields
 	}
-	return tagsMap
+	dst.ctx = src.ctx
+	dst.name = src.name
+	dst.shared = src.shared
 }
 
-func cfgLevelToZap(lvl Level) zapcore.Level {
-	var level zapcore.Level
-	switch lvl {
-	default:
-		level = zap.InfoLevel
-	case LevelWarning:
-		level = zap.WarnLevel
-	case LevelError, LevelCritical, LevelAlert:
-		level = zap.ErrorLevel
-	case LevelEmergency:
-		level = zap.PanicLevel
+// applyDebug forces the most verbose levels in debug mode
+func applyDebug(cfg *Config) {
+	if cfg.Debug {
+		cfg.Level = LevelDebug
+		cfg.RedirectLevel = LevelDebug
 	}
-	return level
 }
 
 // NewZap returns uber/zap logger instance implemented of Logger interface
 func NewZap(ctx context.Context, cfg *Config) *Zap {
-	var (
-		logger *zap.Logger
-	)
-	level := cfgLevelToZap(cfg.Level)
-	tagsMap := parseTagsMap(cfg)
-	if !cfg.Debug {
-		zCfg := zap.Config{
-			Level:       zap.NewAtomicLevelAt(level),
-			Development: false,
-			Sampling: &zap.SamplingConfig{
-				Initial:    100,
-				Thereafter: 100,
-			},
-			Encoding:         "json",
-			EncoderConfig:    zap.NewProductionEncoderConfig(),
-			OutputPaths:      []string{"stdout"},
-			ErrorOutputPaths: []string{"stderr"},
-		}
-		zCfg.EncoderConfig.LevelKey = ""
-		zCfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		logger, _ = zCfg.Build(zap.AddCallerSkip(2), zap.AddStacktrace(zap.PanicLevel))
-	} else {
-		cfg.Level = LevelDebug
-		cfg.RedirectLevel = LevelDebug
-		zCfg := zap.Config{
-			Level:            zap.NewAtomicLevelAt(zap.DebugLevel),
-			Development:      true,
-			Encoding:         "console",
-			EncoderConfig:    zap.NewDevelopmentEncoderConfig(),
-			OutputPaths:      []string{"stdout"},
-			ErrorOutputPaths: []string{"stderr"},
-		}
-		zCfg.EncoderConfig.LevelKey = ""
-		zCfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		logger, _ = zCfg.Build(zap.AddCallerSkip(2))
+	applyDebug(cfg)
+	sh := newShared(cfg, nil, newMetrics())
+	s, e := buildLogger(cfg, sh)
+	if e != nil {
+		fallback := *cfg
+		fallback.Sinks = nil
+		s, _ = buildLogger(&fallback, sh)
+		s.logger.Error(e.Error())
 	}
-	go func(logger *zap.Logger) {
+	out := newOutput(s)
+	sh.out = out
+	go func(out *output) {
 		<-ctx.Done()
-		_ = logger.Sync()
-	}(logger)
-	copyCfg := *cfg
-	z := &Zap{ctx: ctx, cfg: &copyCfg, logger: logger.Sugar(), tagsMap: tagsMap}
-	if !copyCfg.DisableRedirectStdLog {
-		log.SetOutput(&loggerWriter{
-			redirectLevel: &copyCfg.RedirectLevel,
-			logFunc:       z.Log,
-		})
+		_ = out.load().Sync()
+	}(out)
+	z := &Zap{ctx: ctx, shared: sh}
+	if e := z.shared.load().err; e != nil {
+		z.Error("%v", Args(e))
+	}
+	go z.reportSuppressed(ctx)
+	redirected := !cfg.DisableRedirectStdLog
+	if redirected {
+		z.redirectStdLog()
 	}
 	cfg.OnReload(func(ctx context.Context) {
-		z.cfg.DebugTags = cfg.DebugTags
-		z.tagsMap = parseTagsMap(cfg)
+		// debug overrides are applied to own copy, copy of reload is shared by subscribers
+		c := cloneConfig(cfg.loaded())
+		applyDebug(&c)
+		if e := z.shared.store(&c); e != nil {
+			z.Error("%v", Args(e))
+		}
+		if !out.update(&c) {
+			s, e := buildLogger(&c, z.shared)
+			if e != nil {
+				z.Error("%v", Args(e))
+			} else {
+				out.swap(s)
+			}
+		} else if e := out.reopen(); e != nil {
+			z.Error("%v", Args(e))
+		}
+		if redirect := !c.DisableRedirectStdLog; redirect != redirected {
+			redirected = redirect
+			if redirect {
+				z.redirectStdLog()
+			} else {
+				log.SetOutput(os.Stderr)
+			}
+		}
 	})
 	return z
 }
 
-//WrapLogger just wraps zap logger without unnecessary actions and return logger
-func WrapLogger(ctx context.Context, logger *zap.Logger, cfg *Config) *Zap {
-	tagsMap := parseTagsMap(cfg)
-	copyCfg := *cfg
-	z := &Zap{ctx: ctx, cfg: &copyCfg, logger: logger.Sugar(), tagsMap: tagsMap}
+// reportSuppressed periodically logs summary of messages dropped by sampling or rate limit
+func (z *Zap) reportSuppressed(ctx context.Context) {
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-time.After(z.shared.load().limiter.period()):
+		}
+		for _, s := range z.shared.load().limiter.drain(now) {
+			z.Log(s.level, suppressedTemplate, Args(s.count), unlimited(), WithFields(Fields{
+				FieldTemplate:   s.template,
+				FieldSuppressed: s.count,
+			}))
+		}
+	}
+}
 
-	return z
-}
\ No newline at end of file
+// redirectStdLog sets instance as output of standard logger with reloadable redirect level
+func (z *Zap) redirectStdLog() {
+	log.SetOutput(&loggerWriter{
+		redirectLevel: func() Level {
+			return z.shared.load().RedirectLevel
+		},
+		logFunc: z.Log,
+	})
+}
+
+// WrapLogger just wraps zap logger without unnecessary actions and return logger,
+// entries are written into logger with standard zap levels and RFC5424 severity field
+func WrapLogger(ctx context.Context, logger *zap.Logger, cfg *Config) *Zap {
+	logger = logger.WithOptions(zap.WrapCore(adaptCore))
+	return &Zap{
+		ctx:    ctx,
+		shared: newShared(cfg, newOutput(&sinks{logger: logger}), newMetrics()),
+	}
+}
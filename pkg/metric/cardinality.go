@@ -0,0 +1,233 @@
+package metric
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/uber-go/tally"
+)
+
+const (
+	CardinalityCollapse = "collapse"
+	CardinalityDrop     = "drop"
+	// OverflowValue replaces values of tags of metric exceeded limit by collapse policy
+	OverflowValue = "__overflow__"
+
+	MetricCardinalityScope    = "cardinality"
+	MetricCardinalityExceeded = "exceeded"
+	MetricCardinalitySeries   = "series"
+	MetricTagLimit            = "limit"
+	MetricTagPolicy           = "policy"
+
+	limitPerMetric = "metric"
+	limitGlobal    = "global"
+)
+
+// CardinalityCfg is a setting of limits of unique combinations of tag values
+type CardinalityCfg struct {
+	Enabled bool
+	// PerMetric is a limit of combinations of single metric, 0 is unlimited
+	PerMetric int `default:"1000"`
+	// Global is a limit of combinations of all metrics, 0 is unlimited
+	Global int `default:"100000"`
+	// Policy is collapse or drop, new combinations over limit are reported with overflow values or aren't reported
+	Policy string `default:"collapse"`
+}
+
+// guard counts unique combinations of tags of metrics, it's shared by scope and its children
+type guard struct {
+	cfg     atomic.Value
+	mu      sync.Mutex
+	series  map[string]map[string]struct{}
+	total   int
+	warned  map[string]struct{}
+	log     logger.Logger
+	metrics Scope
+}
+
+func newGuard(log logger.Logger, root Scope, cfg CardinalityCfg) *guard {
+	g := &guard{
+		series:  map[string]map[string]struct{}{},
+		warned:  map[string]struct{}{},
+		log:     log,
+		metrics: root.SubScope(MetricCardinalityScope),
+	}
+	g.update(cfg)
+	return g
+}
+
+// update sets limits, known combinations are kept
+func (g *guard) update(cfg CardinalityCfg) {
+	if cfg.Policy != CardinalityDrop {
+		cfg.Policy = CardinalityCollapse
+	}
+	g.cfg.Store(cfg)
+}
+
+func (g *guard) load() CardinalityCfg {
+	return g.cfg.Load().(CardinalityCfg)
+}
+
+// allow returns true if guard is disabled, combination of tags of metric is known or it's within limits,
+// combinations aren't counted while guard is disabled
+func (g *guard) allow(name string, tags map[string]string) bool {
+	cfg := g.load()
+	if !cfg.Enabled {
+		return true
+	}
+	key := tagsKey(tags)
+	g.mu.Lock()
+	known := g.series[name]
+	if _, ok := known[key]; ok {
+		g.mu.Unlock()
+		return true
+	}
+	limit := ""
+	switch {
+	case cfg.PerMetric > 0 && len(known) >= cfg.PerMetric:
+		limit = limitPerMetric
+	case cfg.Global > 0 && g.total >= cfg.Global:
+		limit = limitGlobal
+	}
+	if limit == "" {
+		if known == nil {
+			known = map[string]struct{}{}
+			g.series[name] = known
+		}
+		known[key] = struct{}{}
+		g.total++
+		total := g.total
+		g.mu.Unlock()
+		g.metrics.Gauge(MetricCardinalitySeries).Update(float64(total))
+		return true
+	}
+	_, warned := g.warned[name]
+	g.warned[name] = struct{}{}
+	g.mu.Unlock()
+	g.metrics.Tagged(map[string]string{MetricTagLimit: limit, MetricTagPolicy: cfg.Policy}).Counter(MetricCardinalityExceeded).Inc(1)
+	if !warned {
+		g.log.Warning("metric %s exceeds %s limit of unique tag combinations, new ones are handled by %s policy",
+			logger.Args(name, limit, cfg.Policy))
+	}
+	return false
+}
+
+func tagsKey(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	b := strings.Builder{}
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tags[k])
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// guardScope is Scope limits unique combinations of tags of metrics
+type guardScope struct {
+	guard *guard
+	// base is underlying scope without tags, tagged scope is resolved only for allowed combinations,
+	// so rejected combinations don't create scopes
+	base tally.Scope
+	name string
+	tags map[string]string
+}
+
+// NewGuardScope returns scope limits unique combinations of tag values of metrics, limits are updated by cfg on reload,
+// guard can be enabled or disabled on reload too
+func NewGuardScope(log logger.Logger, scope Scope, cfg *Config) Scope {
+	g := newGuard(log, scope, cfg.Cardinality)
+	cfg.OnReload(func(_ context.Context) {
+		g.update(cfg.loaded().Cardinality)
+	})
+	return &guardScope{guard: g, base: scope}
+}
+
+// metric returns scope for metric, it's false if metric should be dropped
+func (s *guardScope) metric(name string) (tally.Scope, bool) {
+	full := name
+	if s.name != "" {
+		full = s.name + "." + name
+	}
+	if s.guard.allow(full, s.tags) {
+		if len(s.tags) == 0 {
+			return s.base, true
+		}
+		return s.base.Tagged(s.tags), true
+	}
+	if s.guard.load().Policy == CardinalityDrop {
+		return nil, false
+	}
+	overflow := make(map[string]string, len(s.tags))
+	for k := range s.tags {
+		overflow[k] = OverflowValue
+	}
+	return s.base.Tagged(overflow), true
+}
+
+// Counter returns the Counter object corresponding to the name.
+func (s *guardScope) Counter(name string) Counter {
+	if scope, ok := s.metric(name); ok {
+		return scope.Counter(name)
+	}
+	return &counter{}
+}
+
+// Gauge returns the Gauge object corresponding to the name.
+func (s *guardScope) Gauge(name string) Gauge {
+	if scope, ok := s.metric(name); ok {
+		return scope.Gauge(name)
+	}
+	return &gauge{}
+}
+
+// Timer returns the Timer object corresponding to the name.
+func (s *guardScope) Timer(name string) Timer {
+	if scope, ok := s.metric(name); ok {
+		return scope.Timer(name)
+	}
+	return &timer{}
+}
+
+// Histogram returns the Histogram object corresponding to the name.
+func (s *guardScope) Histogram(name string, buckets Buckets) Histogram {
+	if scope, ok := s.metric(name); ok {
+		return scope.Histogram(name, buckets)
+	}
+	return &histogram{}
+}
+
+// Tagged returns a new child scope with the given tags and current tags.
+func (s *guardScope) Tagged(tags map[string]string) Scope {
+	merged := make(map[string]string, len(s.tags)+len(tags))
+	for k, v := range s.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return &guardScope{guard: s.guard, base: s.base, name: s.name, tags: merged}
+}
+
+// SubScope returns a new child scope appending a further name prefix.
+func (s *guardScope) SubScope(name string) Scope {
+	full := name
+	if s.name != "" {
+		full = s.name + "." + name
+	}
+	return &guardScope{guard: s.guard, base: s.base.SubScope(name), name: full, tags: s.tags}
+}
+
+// Capabilities returns a description of metrics reporting capabilities.
+func (s *guardScope) Capabilities() Capabilities {
+	return s.base.Capabilities()
+}
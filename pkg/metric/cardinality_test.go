@@ -0,0 +1,159 @@
+package metric
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger/loggertest"
+	"github.com/uber-go/tally"
+)
+
+func TestGuardScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		expected map[string]int64
+	}{
+		{
+			name:   "collapse",
+			policy: CardinalityCollapse,
+			expected: map[string]int64{
+				"requests+user=0":            1,
+				"requests+user=1":            1,
+				"requests+user=__overflow__": 2,
+			},
+		},
+		{
+			name:   "drop",
+			policy: CardinalityDrop,
+			expected: map[string]int64{
+				"requests+user=0": 1,
+				"requests+user=1": 1,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := tally.NewTestScope("", nil)
+			log := loggertest.NewRecorder(nil)
+			cfg := &Config{
+				invoker:     invoker.NewInvoker(),
+				Cardinality: CardinalityCfg{Enabled: true, PerMetric: 2, Global: 10, Policy: tt.policy},
+			}
+			scope := NewGuardScope(log, root, cfg)
+			for i := 0; i < 4; i++ {
+				scope.Tagged(map[string]string{"user": strconv.Itoa(i)}).Counter("requests").Inc(1)
+			}
+			counters := root.Snapshot().Counters()
+			for key, value := range tt.expected {
+				if c, ok := counters[key]; !ok || c.Value() != value {
+					t.Errorf("counter %s is not reported as %d", key, value)
+				}
+			}
+			if c, ok := counters["cardinality.exceeded+limit=metric,policy="+tt.policy]; !ok || c.Value() != 2 {
+				t.Errorf("exceeded counter is not reported: %v", counters)
+			}
+			if n := len(log.Filter(loggertest.ByMessage("requests exceeds metric limit"))); n != 1 {
+				t.Errorf("expected one warning, got %d", n)
+			}
+		})
+	}
+}
+
+func TestGuardScopeGlobal(t *testing.T) {
+	root := tally.NewTestScope("", nil)
+	log := loggertest.NewRecorder(nil)
+	cfg := &Config{
+		invoker:     invoker.NewInvoker(),
+		Cardinality: CardinalityCfg{Enabled: true, PerMetric: 10, Global: 2, Policy: CardinalityDrop},
+	}
+	scope := NewGuardScope(log, root, cfg).SubScope("http")
+	scope.Tagged(map[string]string{"route": "/a"}).Counter("requests").Inc(1)
+	scope.Tagged(map[string]string{"route": "/a"}).Timer("latency").Record(time.Second)
+	scope.Tagged(map[string]string{"route": "/b"}).Counter("requests").Inc(1)
+	// known combination is allowed after limit is exceeded
+	scope.Tagged(map[string]string{"route": "/a"}).Counter("requests").Inc(1)
+
+	snapshot := root.Snapshot()
+	if c := snapshot.Counters()["http.requests+route=/a"]; c == nil || c.Value() != 2 {
+		t.Errorf("known combination is not reported")
+	}
+	if _, ok := snapshot.Counters()["http.requests+route=/b"]; ok {
+		t.Errorf("combination over global limit is reported")
+	}
+	if g := snapshot.Gauges()["cardinality.series+"]; g == nil || g.Value() != 2 {
+		t.Errorf("series gauge is not reported")
+	}
+
+	cfg.Cardinality.Global = 3
+	cfg.Reload(context.Background())
+	for deadline := time.Now().Add(5 * time.Second); scope.(*guardScope).guard.load().Global != 3; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("limits are not reloaded")
+		}
+	}
+	scope.Tagged(map[string]string{"route": "/c"}).Counter("requests").Inc(1)
+	if _, ok := root.Snapshot().Counters()["http.requests+route=/c"]; !ok {
+		t.Errorf("combination within reloaded limit is not reported")
+	}
+}
+
+// taggedScope records tags of scopes created by Tagged
+type taggedScope struct {
+	tally.Scope
+	tagged *[]map[string]string
+}
+
+func (s *taggedScope) Tagged(tags map[string]string) tally.Scope {
+	*s.tagged = append(*s.tagged, tags)
+	return &taggedScope{Scope: s.Scope.Tagged(tags), tagged: s.tagged}
+}
+
+func TestGuardScopeToggle(t *testing.T) {
+	var tagged []map[string]string
+	root := tally.NewTestScope("", nil)
+	cfg := &Config{
+		invoker:     invoker.NewInvoker(),
+		Cardinality: CardinalityCfg{PerMetric: 1, Policy: CardinalityDrop},
+	}
+	scope := NewGuardScope(loggertest.NewRecorder(nil), &taggedScope{Scope: root, tagged: &tagged}, cfg)
+	requests := func() int {
+		n := 0
+		for key := range root.Snapshot().Counters() {
+			if strings.HasPrefix(key, "requests+") {
+				n++
+			}
+		}
+		return n
+	}
+	// disabled guard passes all combinations
+	for i := 0; i < 3; i++ {
+		scope.Tagged(map[string]string{"user": strconv.Itoa(i)}).Counter("requests").Inc(1)
+	}
+	if n := requests(); n != 3 {
+		t.Errorf("expected 3 combinations by disabled guard, got %d", n)
+	}
+
+	cfg.Cardinality.Enabled = true
+	cfg.Reload(context.Background())
+	for deadline := time.Now().Add(5 * time.Second); !scope.(*guardScope).guard.load().Enabled; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("guard is not enabled on reload")
+		}
+	}
+	tagged = nil
+	for i := 3; i < 6; i++ {
+		scope.Tagged(map[string]string{"user": strconv.Itoa(i)}).Counter("requests").Inc(1)
+	}
+	// scope isn't created for dropped combinations
+	if len(tagged) != 1 || tagged[0]["user"] != "3" {
+		t.Errorf("unexpected tagged scopes: %v", tagged)
+	}
+	if n := requests(); n != 4 {
+		t.Errorf("expected 4 combinations after guard is enabled, got %d", n)
+	}
+}
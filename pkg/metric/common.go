@@ -3,17 +3,32 @@ package metric
 import (
 	"context"
 	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/pkg/errors"
 	"github.com/uber-go/tally"
 	promreporter "github.com/uber-go/tally/prometheus"
 	tallystatsd "github.com/uber-go/tally/statsd"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	Prefix       = "go-core.metric"
 	UnmarshalKey = "metric"
+
+	MetricLoggerScope = "logger"
+
+	ReporterStatsD     = "statsd"
+	ReporterPrometheus = "prometheus"
+	ReporterOTLP       = "otlp"
+
+	defaultPrometheusAddress = "http://0.0.0.0:9090/metrics"
+	defaultReadTimeout       = 10 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultShutdownTimeout   = 5 * time.Second
 )
 
+var ErrUnknownReporter = errors.New("unknown metric reporter")
+
 // StatsDCfg is a setting for tally statsd client
 type StatsDCfg struct {
 	Addr, Prefix  string
@@ -25,17 +40,49 @@ type StatsDCfg struct {
 // PrometheusCfg is a setting for tally prometheus connector
 type PrometheusCfg struct {
 	Address string `default:"http://0.0.0.0:9090/metrics"`
+	// Options of reporter, default registry is used if Registerer and Gatherer are not set
 	Options promreporter.Options
+	// OwnRegistry gives reporter own registry with go and process collectors instead of default one,
+	// so several prometheus reporters of process don't collide
+	OwnRegistry bool
+	// CertFile and KeyFile enable serving metrics by TLS
+	CertFile, KeyFile string
+	// Username and Password enable basic authentication
+	Username, Password string
+	// ReadTimeout, WriteTimeout and ShutdownTimeout of server, defaults are used if not set
+	ReadTimeout, WriteTimeout, ShutdownTimeout time.Duration
 }
 
-// Config is a general metric config settings
-type Config struct {
-	Enabled    bool
+// ReporterCfg is a setting of one of reporters which get metrics of scope simultaneously
+type ReporterCfg struct {
+	// Type is statsd, prometheus or otlp
+	Type string
+	// Prefix, Separator and SanitizeOptions of reporter, settings of general scope are used if not set
+	Prefix          string
+	Separator       string
+	SanitizeOptions *tally.SanitizeOptions
+	// Tags are added to tags of general scope
+	Tags map[string]string
+	// Interval is a flush interval of reporter, general interval is used if not set
+	Interval   time.Duration
 	StatsD     StatsDCfg
 	Prometheus PrometheusCfg
-	Scope      tally.ScopeOptions
-	Interval   time.Duration
-	invoker    *invoker.Invoker
+	OTLP       OTLPCfg
+}
+
+// Config is a general metric config settings
+type Config struct {
+	Enabled     bool
+	StatsD      StatsDCfg
+	Prometheus  PrometheusCfg
+	Reporters   []ReporterCfg
+	Runtime     RuntimeCfg
+	Cardinality CardinalityCfg
+	Scope       tally.ScopeOptions
+	Interval    time.Duration
+	invoker     *invoker.Invoker
+	// snapshot is a copy of config taken on reload, subscribers read it while config is decoded by next reload
+	snapshot atomic.Value
 }
 
 // OnReload
@@ -45,9 +92,19 @@ func (c *Config) OnReload(callback func(ctx context.Context)) {
 
 // Reload
 func (c *Config) Reload(ctx context.Context) {
+	snapshot := *c
+	c.snapshot.Store(&snapshot)
 	c.invoker.Reload(ctx)
 }
 
+// loaded returns copy of config taken on the last reload, config itself is returned before reload
+func (c *Config) loaded() *Config {
+	if s, ok := c.snapshot.Load().(*Config); ok {
+		return s
+	}
+	return c
+}
+
 type (
 	Scope        = tally.Scope
 	Counter      = tally.Counter
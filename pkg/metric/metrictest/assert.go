@@ -0,0 +1,71 @@
+package metrictest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// AssertCounter fails test if value of counter differs from expected one
+func (s *Scope) AssertCounter(t testing.TB, name string, tags map[string]string, expected int64) {
+	t.Helper()
+	if v := s.Capture().Counter(name, tags); v != expected {
+		t.Errorf("counter %s: expected %d, got %d", Key(name, tags), expected, v)
+	}
+}
+
+// AssertGauge fails test if gauge isn't set or its value differs from expected one
+func (s *Scope) AssertGauge(t testing.TB, name string, tags map[string]string, expected float64) {
+	t.Helper()
+	v, ok := s.Capture().Gauge(name, tags)
+	if !ok {
+		t.Errorf("gauge %s is not set", Key(name, tags))
+	} else if v != expected {
+		t.Errorf("gauge %s: expected %v, got %v", Key(name, tags), expected, v)
+	}
+}
+
+// AssertTimer fails test if count of recorded values of timer differs from expected one
+func (s *Scope) AssertTimer(t testing.TB, name string, tags map[string]string, expected int) {
+	t.Helper()
+	if v := s.Capture().Timer(name, tags); len(v) != expected {
+		t.Errorf("timer %s: expected %d values, got %v", Key(name, tags), expected, v)
+	}
+}
+
+// AssertHistogram fails test if counts of value buckets differ from expected ones, buckets are set by upper bounds,
+// the last bucket is Inf, empty buckets may be omitted
+func (s *Scope) AssertHistogram(t testing.TB, name string, tags map[string]string, expected map[float64]int64) {
+	t.Helper()
+	v := s.Capture().Histogram(name, tags)
+	if !reflect.DeepEqual(nonZero(v), nonZero(expected)) {
+		t.Errorf("histogram %s: expected buckets %v, got %v", Key(name, tags), expected, v)
+	}
+}
+
+// AssertDurationHistogram fails test if counts of duration buckets differ from expected ones, empty buckets may be omitted
+func (s *Scope) AssertDurationHistogram(t testing.TB, name string, tags map[string]string, expected map[time.Duration]int64) {
+	t.Helper()
+	v := s.Capture().DurationHistogram(name, tags)
+	e := map[time.Duration]int64{}
+	for bound, n := range expected {
+		if n != 0 {
+			e[bound] = n
+		}
+	}
+	if v == nil {
+		v = map[time.Duration]int64{}
+	}
+	if !reflect.DeepEqual(v, e) {
+		t.Errorf("histogram %s: expected buckets %v, got %v", Key(name, tags), expected, v)
+	}
+}
+
+// AssertNoDiff fails test if metrics are changed between snapshots
+func AssertNoDiff(t testing.TB, before, after Snapshot) {
+	t.Helper()
+	d := Diff(before, after)
+	if len(d.Counters)+len(d.Gauges)+len(d.Timers)+len(d.Histograms)+len(d.DurationHistograms) > 0 {
+		t.Errorf("metrics are changed: %+v", d)
+	}
+}
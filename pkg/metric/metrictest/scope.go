@@ -0,0 +1,180 @@
+// Package metrictest provides in-memory metric scope with assertion helpers for tests
+package metrictest
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+// Inf is upper bound of the last bucket of value histogram
+var Inf = math.MaxFloat64
+
+// Scope is tally test scope records all emitted metrics, children of scope share records
+type Scope struct {
+	tally.TestScope
+	mu sync.Mutex
+	// snapshot of tally consumes samples of histograms, so they are accumulated by scope
+	histograms         map[string]map[float64]int64
+	durationHistograms map[string]map[time.Duration]int64
+}
+
+// NewScope returns scope with prefix and tags, they are included into names and tags of metrics
+func NewScope(prefix string, tags map[string]string) *Scope {
+	return &Scope{
+		TestScope:          tally.NewTestScope(prefix, tags),
+		histograms:         map[string]map[float64]int64{},
+		durationHistograms: map[string]map[time.Duration]int64{},
+	}
+}
+
+// Key returns key of metric in snapshot by full name and all tags, e.g. http.requests+method=get
+func Key(name string, tags map[string]string) string {
+	return tally.KeyForPrefixedStringMap(name, tags)
+}
+
+// Snapshot is a copy of values of metrics by keys
+type Snapshot struct {
+	Counters           map[string]int64
+	Gauges             map[string]float64
+	Timers             map[string][]time.Duration
+	Histograms         map[string]map[float64]int64
+	DurationHistograms map[string]map[time.Duration]int64
+}
+
+// Capture returns copy of current values of metrics, counters and histograms are cumulative,
+// histograms are counted by captures of scope only, so snapshot of tally shouldn't be taken directly
+func (s *Scope) Capture() Snapshot {
+	snapshot := s.TestScope.Snapshot()
+	c := Snapshot{
+		Counters:           map[string]int64{},
+		Gauges:             map[string]float64{},
+		Timers:             map[string][]time.Duration{},
+		Histograms:         map[string]map[float64]int64{},
+		DurationHistograms: map[string]map[time.Duration]int64{},
+	}
+	for k, v := range snapshot.Counters() {
+		c.Counters[k] = v.Value()
+	}
+	for k, v := range snapshot.Gauges() {
+		c.Gauges[k] = v.Value()
+	}
+	for k, v := range snapshot.Timers() {
+		c.Timers[k] = append([]time.Duration(nil), v.Values()...)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range snapshot.Histograms() {
+		for bound, n := range v.Values() {
+			if n != 0 {
+				if s.histograms[k] == nil {
+					s.histograms[k] = map[float64]int64{}
+				}
+				s.histograms[k][bound] += n
+			}
+		}
+		for bound, n := range v.Durations() {
+			if n != 0 {
+				if s.durationHistograms[k] == nil {
+					s.durationHistograms[k] = map[time.Duration]int64{}
+				}
+				s.durationHistograms[k][bound] += n
+			}
+		}
+	}
+	for k, buckets := range s.histograms {
+		c.Histograms[k] = nonZero(buckets)
+	}
+	for k, buckets := range s.durationHistograms {
+		c.DurationHistograms[k] = map[time.Duration]int64{}
+		for bound, n := range buckets {
+			c.DurationHistograms[k][bound] = n
+		}
+	}
+	return c
+}
+
+// Counter returns value of counter
+func (s Snapshot) Counter(name string, tags map[string]string) int64 {
+	return s.Counters[Key(name, tags)]
+}
+
+// Gauge returns value of gauge and true if gauge is set
+func (s Snapshot) Gauge(name string, tags map[string]string) (float64, bool) {
+	v, ok := s.Gauges[Key(name, tags)]
+	return v, ok
+}
+
+// Timer returns recorded values of timer
+func (s Snapshot) Timer(name string, tags map[string]string) []time.Duration {
+	return s.Timers[Key(name, tags)]
+}
+
+// Histogram returns counts of samples by upper bounds of value buckets, empty buckets are omitted
+func (s Snapshot) Histogram(name string, tags map[string]string) map[float64]int64 {
+	return s.Histograms[Key(name, tags)]
+}
+
+// DurationHistogram returns counts of samples by upper bounds of duration buckets, empty buckets are omitted
+func (s Snapshot) DurationHistogram(name string, tags map[string]string) map[time.Duration]int64 {
+	return s.DurationHistograms[Key(name, tags)]
+}
+
+// Diff returns changes between snapshots: deltas of counters and buckets, changed gauges and new timer values
+func Diff(before, after Snapshot) Snapshot {
+	d := Snapshot{
+		Counters:           map[string]int64{},
+		Gauges:             map[string]float64{},
+		Timers:             map[string][]time.Duration{},
+		Histograms:         map[string]map[float64]int64{},
+		DurationHistograms: map[string]map[time.Duration]int64{},
+	}
+	for k, v := range after.Counters {
+		if delta := v - before.Counters[k]; delta != 0 {
+			d.Counters[k] = delta
+		}
+	}
+	for k, v := range after.Gauges {
+		if prev, ok := before.Gauges[k]; !ok || prev != v {
+			d.Gauges[k] = v
+		}
+	}
+	for k, v := range after.Timers {
+		if n := len(before.Timers[k]); len(v) > n {
+			d.Timers[k] = v[n:]
+		}
+	}
+	for k, buckets := range after.Histograms {
+		delta := map[float64]int64{}
+		for bound, n := range buckets {
+			delta[bound] = n - before.Histograms[k][bound]
+		}
+		if delta = nonZero(delta); len(delta) > 0 {
+			d.Histograms[k] = delta
+		}
+	}
+	for k, buckets := range after.DurationHistograms {
+		delta := map[time.Duration]int64{}
+		for bound, n := range buckets {
+			if n -= before.DurationHistograms[k][bound]; n != 0 {
+				delta[bound] = n
+			}
+		}
+		if len(delta) > 0 {
+			d.DurationHistograms[k] = delta
+		}
+	}
+	return d
+}
+
+func nonZero(buckets map[float64]int64) map[float64]int64 {
+	m := map[float64]int64{}
+	for bound, n := range buckets {
+		if n != 0 {
+			m[bound] = n
+		}
+	}
+	return m
+}
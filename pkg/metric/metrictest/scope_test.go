@@ -0,0 +1,39 @@
+package metrictest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+func TestScope(t *testing.T) {
+	s := NewScope("", nil)
+	http := s.SubScope("http").Tagged(map[string]string{"method": "get"})
+	tags := map[string]string{"method": "get", "code": "200"}
+
+	http.Tagged(map[string]string{"code": "200"}).Counter("requests").Inc(2)
+	before := s.Capture()
+	http.Tagged(map[string]string{"code": "200"}).Counter("requests").Inc(1)
+	http.Gauge("inflight").Update(4)
+	http.Timer("latency").Record(time.Second)
+	http.Histogram("size", tally.ValueBuckets{10, 100}).RecordValue(50)
+	http.Histogram("size", tally.ValueBuckets{10, 100}).RecordValue(500)
+	http.Histogram("wait", tally.DurationBuckets{time.Second}).RecordDuration(time.Millisecond)
+
+	get := map[string]string{"method": "get"}
+	s.AssertCounter(t, "http.requests", tags, 3)
+	s.AssertGauge(t, "http.inflight", get, 4)
+	s.AssertTimer(t, "http.latency", get, 1)
+	s.AssertHistogram(t, "http.size", get, map[float64]int64{100: 1, Inf: 1})
+	s.AssertDurationHistogram(t, "http.wait", get, map[time.Duration]int64{time.Second: 1})
+
+	d := Diff(before, s.Capture())
+	if n := d.Counter("http.requests", tags); n != 1 {
+		t.Errorf("expected counter delta 1, got %d", n)
+	}
+	if v, ok := d.Gauge("http.inflight", get); !ok || v != 4 {
+		t.Errorf("expected changed gauge, got %v", v)
+	}
+	AssertNoDiff(t, s.Capture(), s.Capture())
+}
@@ -111,12 +111,22 @@ func (t *Mock) Histogram(name string, buckets Buckets) Histogram {
 
 // Tagged returns a new child scope with the given tags and current tags.
 func (t *Mock) Tagged(tags map[string]string) Scope {
-	return &Mock{tags: tags}
+	merged := make(map[string]string, len(t.tags)+len(tags))
+	for k, v := range t.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return &Mock{tags: merged, name: t.name}
 }
 
 // SubScope returns a new child scope appending a further name prefix.
 func (t *Mock) SubScope(name string) Scope {
-	return &Mock{name: name}
+	if t.name != "" {
+		name = t.name + "." + name
+	}
+	return &Mock{tags: t.tags, name: name}
 }
 
 // Capabilities returns a description of metrics reporting capabilities.
@@ -0,0 +1,159 @@
+package metric
+
+import (
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+// multiScope is Scope forwards metrics into several scopes, e.g. root scopes of different reporters
+type multiScope struct {
+	scopes []tally.Scope
+}
+
+// NewMultiScope returns scope forwards metrics into all scopes
+func NewMultiScope(scopes ...tally.Scope) Scope {
+	return &multiScope{scopes: scopes}
+}
+
+// Counter returns the Counter object corresponding to the name.
+func (s *multiScope) Counter(name string) Counter {
+	c := make(multiCounter, len(s.scopes))
+	for i, scope := range s.scopes {
+		c[i] = scope.Counter(name)
+	}
+	return c
+}
+
+// Gauge returns the Gauge object corresponding to the name.
+func (s *multiScope) Gauge(name string) Gauge {
+	g := make(multiGauge, len(s.scopes))
+	for i, scope := range s.scopes {
+		g[i] = scope.Gauge(name)
+	}
+	return g
+}
+
+// Timer returns the Timer object corresponding to the name.
+func (s *multiScope) Timer(name string) Timer {
+	t := make(multiTimer, len(s.scopes))
+	for i, scope := range s.scopes {
+		t[i] = scope.Timer(name)
+	}
+	return t
+}
+
+// Histogram returns the Histogram object corresponding to the name.
+func (s *multiScope) Histogram(name string, buckets Buckets) Histogram {
+	h := make(multiHistogram, len(s.scopes))
+	for i, scope := range s.scopes {
+		h[i] = scope.Histogram(name, buckets)
+	}
+	return h
+}
+
+// Tagged returns a new child scope with the given tags and current tags.
+func (s *multiScope) Tagged(tags map[string]string) Scope {
+	child := &multiScope{scopes: make([]tally.Scope, len(s.scopes))}
+	for i, scope := range s.scopes {
+		child.scopes[i] = scope.Tagged(tags)
+	}
+	return child
+}
+
+// SubScope returns a new child scope appending a further name prefix.
+func (s *multiScope) SubScope(name string) Scope {
+	child := &multiScope{scopes: make([]tally.Scope, len(s.scopes))}
+	for i, scope := range s.scopes {
+		child.scopes[i] = scope.SubScope(name)
+	}
+	return child
+}
+
+// Capabilities returns a description of metrics reporting capabilities, a capability is present if any scope has it.
+func (s *multiScope) Capabilities() Capabilities {
+	c := &multiCapabilities{}
+	for _, scope := range s.scopes {
+		sc := scope.Capabilities()
+		c.reporting = c.reporting || sc.Reporting()
+		c.tagging = c.tagging || sc.Tagging()
+	}
+	return c
+}
+
+type multiCounter []tally.Counter
+
+// Inc increments the counter by a delta.
+func (c multiCounter) Inc(delta int64) {
+	for _, counter := range c {
+		counter.Inc(delta)
+	}
+}
+
+type multiGauge []tally.Gauge
+
+// Update sets the gauges absolute value.
+func (g multiGauge) Update(value float64) {
+	for _, gauge := range g {
+		gauge.Update(value)
+	}
+}
+
+type multiTimer []tally.Timer
+
+// Record a specific duration directly.
+func (t multiTimer) Record(value time.Duration) {
+	for _, timer := range t {
+		timer.Record(value)
+	}
+}
+
+// Start gives you back a specific point in time to report via Stop.
+func (t multiTimer) Start() Stopwatch {
+	return tally.NewStopwatch(time.Now(), t)
+}
+
+// RecordStopwatch
+func (t multiTimer) RecordStopwatch(stopwatchStart time.Time) {
+	t.Record(time.Since(stopwatchStart))
+}
+
+type multiHistogram []tally.Histogram
+
+// RecordValue records a specific value directly.
+func (h multiHistogram) RecordValue(value float64) {
+	for _, histogram := range h {
+		histogram.RecordValue(value)
+	}
+}
+
+// RecordDuration records a specific duration directly.
+func (h multiHistogram) RecordDuration(value time.Duration) {
+	for _, histogram := range h {
+		histogram.RecordDuration(value)
+	}
+}
+
+// Start gives you a specific point in time to then record a duration.
+func (h multiHistogram) Start() Stopwatch {
+	return tally.NewStopwatch(time.Now(), h)
+}
+
+// RecordStopwatch
+func (h multiHistogram) RecordStopwatch(stopwatchStart time.Time) {
+	h.RecordDuration(time.Since(stopwatchStart))
+}
+
+type multiCapabilities struct {
+	reporting, tagging bool
+}
+
+// Reporting returns whether the reporter has the ability to actively report.
+func (c *multiCapabilities) Reporting() bool {
+	return c.reporting
+}
+
+// Tagging returns whether the reporter has the capability for tagged metrics.
+func (c *multiCapabilities) Tagging() bool {
+	return c.tagging
+}
@@ -0,0 +1,87 @@
+package metric
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+// statsReporter keeps reported values by metric names
+type statsReporter struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func (r *statsReporter) add(name string, value float64) {
+	r.mu.Lock()
+	r.values[name] += value
+	r.mu.Unlock()
+}
+
+func (r *statsReporter) ReportCounter(name string, tags map[string]string, value int64) {
+	r.add(name, float64(value))
+}
+
+func (r *statsReporter) ReportGauge(name string, tags map[string]string, value float64) {
+	r.add(name, value)
+}
+
+func (r *statsReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {
+	r.add(name, interval.Seconds())
+}
+
+func (r *statsReporter) ReportHistogramValueSamples(name string, tags map[string]string, buckets tally.Buckets, bucketLowerBound, bucketUpperBound float64, samples int64) {
+	r.add(name, float64(samples))
+}
+
+func (r *statsReporter) ReportHistogramDurationSamples(name string, tags map[string]string, buckets tally.Buckets, bucketLowerBound, bucketUpperBound time.Duration, samples int64) {
+	r.add(name, float64(samples))
+}
+
+func (r *statsReporter) Capabilities() tally.Capabilities {
+	return r
+}
+
+func (r *statsReporter) Reporting() bool {
+	return true
+}
+
+func (r *statsReporter) Tagging() bool {
+	return false
+}
+
+func (r *statsReporter) Flush() {}
+
+func TestMultiScope(t *testing.T) {
+	test := tally.NewTestScope("a", map[string]string{"env": "test"})
+	reporter := &statsReporter{values: map[string]float64{}}
+	plain, closer := tally.NewRootScope(tally.ScopeOptions{Prefix: "b", Reporter: reporter}, time.Hour)
+
+	scope := NewMultiScope(test, plain).SubScope("api").Tagged(map[string]string{"method": "get"})
+	scope.Counter("requests").Inc(2)
+	scope.Gauge("inflight").Update(3)
+	scope.Timer("latency").Record(time.Second)
+	scope.Histogram("size", tally.ValueBuckets{10, 100}).RecordValue(50)
+	if !scope.Capabilities().Reporting() {
+		t.Error("reporting capability of plain reporter is lost")
+	}
+	if e := closer.Close(); e != nil {
+		t.Fatal(e)
+	}
+
+	snapshot := test.Snapshot()
+	if c, ok := snapshot.Counters()["a.api.requests+env=test,method=get"]; !ok || c.Value() != 2 {
+		t.Errorf("unexpected counters of test scope: %v", snapshot.Counters())
+	}
+	if g, ok := snapshot.Gauges()["a.api.inflight+env=test,method=get"]; !ok || g.Value() != 3 {
+		t.Errorf("unexpected gauges of test scope: %v", snapshot.Gauges())
+	}
+	expected := map[string]float64{"b.api.requests": 2, "b.api.inflight": 3, "b.api.latency": 1, "b.api.size": 1}
+	for name, value := range expected {
+		if reporter.values[name] != value {
+			t.Errorf("expected %v of %s, got %v", value, name, reporter.values[name])
+		}
+	}
+}
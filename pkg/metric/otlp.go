@@ -0,0 +1,403 @@
+package metric
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/uber-go/tally"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	OTLPProtocolHTTP = "http"
+	OTLPProtocolGRPC = "grpc"
+	// OTLPExportMethod is a full name of export method of OTLP metrics service
+	OTLPExportMethod   = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	otlpHTTPPath       = "/v1/metrics"
+	otlpScopeName      = "github.com/ProtocolONE/go-core/v2/pkg/metric"
+	defaultOTLPTimeout = 10 * time.Second
+)
+
+var (
+	ErrUnknownProtocol = errors.New("unknown otlp protocol")
+	ErrExport          = errors.New("otlp export is failed")
+)
+
+// OTLPCfg is a setting of OpenTelemetry exporter
+type OTLPCfg struct {
+	// Protocol is http or grpc
+	Protocol string
+	// Endpoint is url of http receiver, e.g. http://collector:4318, or address of grpc receiver, e.g. collector:4317
+	Endpoint string
+	// Insecure disables TLS of grpc connection
+	Insecure bool
+	// Headers are sent with every export request
+	Headers map[string]string
+	// Timeout of export request, default is used if not set
+	Timeout time.Duration
+	// Resource are attributes of resource, e.g. service.name, it's set by name of executable if not configured
+	Resource map[string]string
+}
+
+// DottedKeysHookFunc returns decoder func hook for flattening nested maps into map[string]string with dotted keys,
+// configurator splits dotted keys, so attribute service.name is decoded as nested map
+func DottedKeysHookFunc() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.Map || t != reflect.TypeOf(map[string]string{}) {
+			return data, nil
+		}
+		flat := map[string]interface{}{}
+		flattenKeys(flat, "", reflect.ValueOf(data))
+		return flat, nil
+	}
+}
+
+func flattenKeys(dst map[string]interface{}, prefix string, v reflect.Value) {
+	for _, k := range v.MapKeys() {
+		key := fmt.Sprint(k.Interface())
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		val := v.MapIndex(k)
+		for val.Kind() == reflect.Interface {
+			val = val.Elem()
+		}
+		if val.Kind() == reflect.Map {
+			flattenKeys(dst, key, val)
+		} else if val.IsValid() {
+			dst[key] = val.Interface()
+		}
+	}
+}
+
+// otlpKind is a type of OTLP metric data
+type otlpKind int
+
+const (
+	otlpSum otlpKind = iota
+	otlpGauge
+	otlpHistogram
+)
+
+// otlpPoint is a data point aggregated between flushes
+type otlpPoint struct {
+	kind   otlpKind
+	name   string
+	unit   string
+	tags   map[string]string
+	delta  int64
+	value  float64
+	count  uint64
+	sum    float64
+	min    float64
+	max    float64
+	bounds []float64
+	counts []uint64
+}
+
+// otlpSender sends encoded export request
+type otlpSender interface {
+	send(ctx context.Context, body []byte) error
+	io.Closer
+}
+
+// otlpReporter is tally.StatsReporter exports metrics into OpenTelemetry collector, counters are exported as
+// monotonic delta sums, gauges as gauges, timers and histograms as delta histograms
+type otlpReporter struct {
+	mu       sync.Mutex
+	points   map[string]*otlpPoint
+	start    time.Time
+	resource map[string]string
+	timeout  time.Duration
+	sender   otlpSender
+	log      logger.Logger
+}
+
+// NewOTLPReporter returns reporter exports metrics by OTLP/HTTP or OTLP/gRPC, it's closed along with root scope
+func NewOTLPReporter(log logger.Logger, cfg OTLPCfg) (tally.StatsReporter, error) {
+	var (
+		sender otlpSender
+		e      error
+	)
+	switch cfg.Protocol {
+	case "", OTLPProtocolHTTP:
+		sender, e = newOTLPHTTPSender(cfg)
+	case OTLPProtocolGRPC:
+		sender, e = newOTLPGRPCSender(cfg)
+	default:
+		e = errors.WithMessage(ErrUnknownProtocol, cfg.Protocol)
+	}
+	if e != nil {
+		return nil, e
+	}
+	resource := map[string]string{}
+	for k, v := range cfg.Resource {
+		resource[k] = v
+	}
+	if _, ok := resource["service.name"]; !ok {
+		resource["service.name"] = "unknown_service:" + filepath.Base(os.Args[0])
+	}
+	return &otlpReporter{
+		points:   map[string]*otlpPoint{},
+		start:    time.Now(),
+		resource: resource,
+		timeout:  durationOr(cfg.Timeout, defaultOTLPTimeout),
+		sender:   sender,
+		log:      log,
+	}, nil
+}
+
+// point returns aggregated point of metric, caller must hold lock
+func (r *otlpReporter) point(kind otlpKind, name string, tags map[string]string) *otlpPoint {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	id := strings.Builder{}
+	fmt.Fprintf(&id, "%d|%s", kind, name)
+	for _, k := range keys {
+		id.WriteString("|" + k + "=" + tags[k])
+	}
+	p, ok := r.points[id.String()]
+	if !ok {
+		p = &otlpPoint{kind: kind, name: name, tags: tags, min: math.Inf(1), max: math.Inf(-1)}
+		r.points[id.String()] = p
+	}
+	return p
+}
+
+// ReportCounter reports a counter value
+func (r *otlpReporter) ReportCounter(name string, tags map[string]string, value int64) {
+	r.mu.Lock()
+	r.point(otlpSum, name, tags).delta += value
+	r.mu.Unlock()
+}
+
+// ReportGauge reports a gauge value
+func (r *otlpReporter) ReportGauge(name string, tags map[string]string, value float64) {
+	r.mu.Lock()
+	r.point(otlpGauge, name, tags).value = value
+	r.mu.Unlock()
+}
+
+// ReportTimer reports a timer value
+func (r *otlpReporter) ReportTimer(name string, tags map[string]string, interval time.Duration) {
+	r.mu.Lock()
+	p := r.point(otlpHistogram, name, tags)
+	p.unit = "s"
+	v := interval.Seconds()
+	p.count++
+	p.sum += v
+	p.min = math.Min(p.min, v)
+	p.max = math.Max(p.max, v)
+	p.counts = []uint64{p.count}
+	r.mu.Unlock()
+}
+
+// ReportHistogramValueSamples reports histogram samples for a bucket
+func (r *otlpReporter) ReportHistogramValueSamples(
+	name string, tags map[string]string, buckets tally.Buckets, bucketLowerBound, bucketUpperBound float64, samples int64,
+) {
+	r.reportBucket(name, "", tags, buckets.AsValues(), bucketUpperBound, samples)
+}
+
+// ReportHistogramDurationSamples reports histogram samples for a bucket
+func (r *otlpReporter) ReportHistogramDurationSamples(
+	name string, tags map[string]string, buckets tally.Buckets, bucketLowerBound, bucketUpperBound time.Duration, samples int64,
+) {
+	durations := buckets.AsDurations()
+	bounds := make([]float64, len(durations))
+	for i, d := range durations {
+		bounds[i] = d.Seconds()
+	}
+	upper := math.MaxFloat64
+	if bucketUpperBound != time.Duration(math.MaxInt64) {
+		upper = bucketUpperBound.Seconds()
+	}
+	r.reportBucket(name, "s", tags, bounds, upper, samples)
+}
+
+// reportBucket adds samples into bucket of histogram, bucket is found by upper bound, the last bucket is unbounded
+func (r *otlpReporter) reportBucket(name, unit string, tags map[string]string, bounds []float64, upper float64, samples int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := r.point(otlpHistogram, name, tags)
+	if p.bounds == nil {
+		p.unit = unit
+		p.bounds = bounds
+		p.counts = make([]uint64, len(bounds)+1)
+	}
+	i := sort.SearchFloat64s(p.bounds, upper)
+	if i < len(p.bounds) && p.bounds[i] != upper {
+		i = len(p.bounds)
+	}
+	p.counts[i] += uint64(samples)
+	p.count += uint64(samples)
+}
+
+// Capabilities returns the capabilities description of the reporter.
+func (r *otlpReporter) Capabilities() tally.Capabilities {
+	return r
+}
+
+// Reporting returns whether the reporter has the ability to actively report.
+func (r *otlpReporter) Reporting() bool {
+	return true
+}
+
+// Tagging returns whether the reporter has the capability for tagged metrics.
+func (r *otlpReporter) Tagging() bool {
+	return true
+}
+
+// Flush exports metrics reported since the previous flush
+func (r *otlpReporter) Flush() {
+	r.mu.Lock()
+	points, start, now := r.points, r.start, time.Now()
+	r.points, r.start = map[string]*otlpPoint{}, now
+	r.mu.Unlock()
+	if len(points) == 0 {
+		return
+	}
+	body := encodeOTLPRequest(r.resource, points, start, now)
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	if e := r.sender.send(ctx, body); e != nil {
+		r.log.Error("%v", logger.Args(errors.WithMessage(e, Prefix+": otlp")))
+	}
+}
+
+// Close closes connection to collector
+func (r *otlpReporter) Close() error {
+	return r.sender.Close()
+}
+
+// otlpHTTPSender sends export requests by OTLP/HTTP in binary protobuf encoding
+type otlpHTTPSender struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newOTLPHTTPSender(cfg OTLPCfg) (*otlpHTTPSender, error) {
+	u, e := url.Parse(cfg.Endpoint)
+	if e != nil {
+		return nil, e
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpHTTPPath
+	}
+	return &otlpHTTPSender{url: u.String(), headers: cfg.Headers, client: &http.Client{}}, nil
+}
+
+func (s *otlpHTTPSender) send(ctx context.Context, body []byte) error {
+	req, e := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if e != nil {
+		return e
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+	resp, e := s.client.Do(req)
+	if e != nil {
+		return e
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.WithMessage(ErrExport, resp.Status)
+	}
+	return nil
+}
+
+// Close
+func (s *otlpHTTPSender) Close() error {
+	return nil
+}
+
+// otlpGRPCSender sends export requests by OTLP/gRPC, messages are encoded by reporter
+type otlpGRPCSender struct {
+	conn    *grpc.ClientConn
+	headers metadata.MD
+}
+
+func newOTLPGRPCSender(cfg OTLPCfg) (*otlpGRPCSender, error) {
+	opts := []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.ForceCodec(rawCodec{}))}
+	if cfg.Insecure {
+		opts = append(opts, grpc.WithInsecure())
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{})))
+	}
+	conn, e := grpc.Dial(cfg.Endpoint, opts...)
+	if e != nil {
+		return nil, e
+	}
+	return &otlpGRPCSender{conn: conn, headers: metadata.New(cfg.Headers)}, nil
+}
+
+func (s *otlpGRPCSender) send(ctx context.Context, body []byte) error {
+	if len(s.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, s.headers)
+	}
+	var resp []byte
+	return s.conn.Invoke(ctx, OTLPExportMethod, body, &resp)
+}
+
+// Close
+func (s *otlpGRPCSender) Close() error {
+	return s.conn.Close()
+}
+
+// rawCodec is grpc codec of messages encoded in advance, it's named proto for compatibility with receivers
+type rawCodec struct{}
+
+// Marshal
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case []byte:
+		return m, nil
+	case *[]byte:
+		return *m, nil
+	}
+	return nil, fmt.Errorf("unexpected message type %T", v)
+}
+
+// Unmarshal
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*m = append((*m)[:0], data...)
+	return nil
+}
+
+// Name
+func (rawCodec) Name() string {
+	return "proto"
+}
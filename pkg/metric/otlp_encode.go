@@ -0,0 +1,172 @@
+package metric
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// field numbers of opentelemetry/proto/metrics/v1 messages, messages are encoded without generated code
+const (
+	pbVarint  = 0
+	pbFixed64 = 1
+	pbBytes   = 2
+
+	otlpAggregationDelta = 1
+)
+
+// pbWriter writes protobuf fields into buffer
+type pbWriter struct {
+	*proto.Buffer
+}
+
+func newPBWriter() pbWriter {
+	return pbWriter{proto.NewBuffer(nil)}
+}
+
+func (w pbWriter) tag(field, wire int) {
+	_ = w.EncodeVarint(uint64(field)<<3 | uint64(wire))
+}
+
+func (w pbWriter) varint(field int, v uint64) {
+	w.tag(field, pbVarint)
+	_ = w.EncodeVarint(v)
+}
+
+func (w pbWriter) fixed64(field int, v uint64) {
+	w.tag(field, pbFixed64)
+	_ = w.EncodeFixed64(v)
+}
+
+func (w pbWriter) double(field int, v float64) {
+	w.fixed64(field, math.Float64bits(v))
+}
+
+func (w pbWriter) str(field int, s string) {
+	w.tag(field, pbBytes)
+	_ = w.EncodeStringBytes(s)
+}
+
+// message writes embedded message built by fn
+func (w pbWriter) message(field int, fn func(w pbWriter)) {
+	child := newPBWriter()
+	fn(child)
+	w.tag(field, pbBytes)
+	_ = w.EncodeRawBytes(child.Bytes())
+}
+
+// packed writes packed repeated fixed64 values
+func (w pbWriter) packed(field int, values []uint64) {
+	w.message(field, func(w pbWriter) {
+		for _, v := range values {
+			_ = w.EncodeFixed64(v)
+		}
+	})
+}
+
+// attributes writes KeyValue messages with string values sorted by keys
+func (w pbWriter) attributes(field int, attrs map[string]string) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := attrs[k]
+		w.message(field, func(w pbWriter) {
+			w.str(1, k)
+			w.message(2, func(w pbWriter) {
+				w.str(1, v)
+			})
+		})
+	}
+}
+
+// encodeOTLPRequest returns encoded ExportMetricsServiceRequest
+func encodeOTLPRequest(resource map[string]string, points map[string]*otlpPoint, start, now time.Time) []byte {
+	keys := make([]string, 0, len(points))
+	for k := range points {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	req := newPBWriter()
+	// ResourceMetrics
+	req.message(1, func(w pbWriter) {
+		// Resource
+		w.message(1, func(w pbWriter) {
+			w.attributes(1, resource)
+		})
+		// ScopeMetrics
+		w.message(2, func(w pbWriter) {
+			w.message(1, func(w pbWriter) {
+				w.str(1, otlpScopeName)
+			})
+			for _, k := range keys {
+				p := points[k]
+				w.message(2, func(w pbWriter) {
+					encodeOTLPMetric(w, p, start, now)
+				})
+			}
+		})
+	})
+	return req.Bytes()
+}
+
+// encodeOTLPMetric writes fields of Metric message
+func encodeOTLPMetric(w pbWriter, p *otlpPoint, start, now time.Time) {
+	w.str(1, p.name)
+	if p.unit != "" {
+		w.str(3, p.unit)
+	}
+	startNano, nowNano := uint64(start.UnixNano()), uint64(now.UnixNano())
+	number := func(w pbWriter) {
+		w.fixed64(2, startNano)
+		w.fixed64(3, nowNano)
+		if p.kind == otlpSum {
+			w.fixed64(6, uint64(p.delta))
+		} else {
+			w.double(4, p.value)
+		}
+		w.attributes(7, p.tags)
+	}
+	switch p.kind {
+	case otlpGauge:
+		w.message(5, func(w pbWriter) {
+			w.message(1, number)
+		})
+	case otlpSum:
+		w.message(7, func(w pbWriter) {
+			w.message(1, number)
+			w.varint(2, otlpAggregationDelta)
+			w.varint(3, 1)
+		})
+	case otlpHistogram:
+		w.message(9, func(w pbWriter) {
+			w.message(1, func(w pbWriter) {
+				w.fixed64(2, startNano)
+				w.fixed64(3, nowNano)
+				w.fixed64(4, p.count)
+				// sum, min and max are known for timers only
+				if p.bounds == nil {
+					w.double(5, p.sum)
+				}
+				w.packed(6, p.counts)
+				if len(p.bounds) > 0 {
+					bounds := make([]uint64, len(p.bounds))
+					for i, b := range p.bounds {
+						bounds[i] = math.Float64bits(b)
+					}
+					w.packed(7, bounds)
+				}
+				w.attributes(9, p.tags)
+				if p.bounds == nil {
+					w.double(11, p.min)
+					w.double(12, p.max)
+				}
+			})
+			w.varint(2, otlpAggregationDelta)
+		})
+	}
+}
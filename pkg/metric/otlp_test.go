@@ -0,0 +1,176 @@
+package metric
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/golang/protobuf/proto"
+	"github.com/uber-go/tally"
+	"google.golang.org/grpc"
+)
+
+// otlpRequest is decoded content of export request
+type otlpRequest struct {
+	resource map[string]string
+	// metrics are field numbers of data by names of metrics
+	metrics map[string]uint64
+}
+
+// pbFields returns fields of message, values of bytes fields are returned as is
+func pbFields(t *testing.T, b []byte) map[uint64][][]byte {
+	fields := map[uint64][][]byte{}
+	for i := 0; i < len(b); {
+		key, n := proto.DecodeVarint(b[i:])
+		if n == 0 {
+			t.Fatal("invalid key")
+		}
+		i += n
+		var v []byte
+		switch key & 7 {
+		case pbVarint:
+			_, n = proto.DecodeVarint(b[i:])
+			i += n
+		case pbFixed64:
+			i += 8
+		case pbBytes:
+			l, n := proto.DecodeVarint(b[i:])
+			i += n
+			v = b[i : i+int(l)]
+			i += int(l)
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+		fields[key>>3] = append(fields[key>>3], v)
+	}
+	return fields
+}
+
+func decodeOTLPRequest(t *testing.T, b []byte) otlpRequest {
+	req := otlpRequest{resource: map[string]string{}, metrics: map[string]uint64{}}
+	for _, rm := range pbFields(t, b)[1] {
+		rmf := pbFields(t, rm)
+		for _, kv := range pbFields(t, rmf[1][0])[1] {
+			kvf := pbFields(t, kv)
+			req.resource[string(kvf[1][0])] = string(pbFields(t, kvf[2][0])[1][0])
+		}
+		for _, sm := range rmf[2] {
+			for _, m := range pbFields(t, sm)[2] {
+				mf := pbFields(t, m)
+				for _, data := range []uint64{5, 7, 9} {
+					if _, ok := mf[data]; ok {
+						req.metrics[string(mf[1][0])] = data
+					}
+				}
+			}
+		}
+	}
+	return req
+}
+
+func reportOTLP(t *testing.T, cfg OTLPCfg) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reporter, e := NewOTLPReporter(logger.NewMock(ctx, &logger.Config{}, false), cfg)
+	if e != nil {
+		t.Fatal(e)
+	}
+	scope, closer := tally.NewRootScope(tally.ScopeOptions{Prefix: "app", Reporter: reporter}, time.Hour)
+	scope.Counter("requests").Inc(1)
+	scope.Gauge("inflight").Update(2)
+	scope.Timer("latency").Record(time.Second)
+	scope.Histogram("size", tally.ValueBuckets{10, 100}).RecordValue(50)
+	if e := closer.Close(); e != nil {
+		t.Fatal(e)
+	}
+}
+
+func checkOTLPRequest(t *testing.T, req otlpRequest) {
+	if req.resource["service.name"] != "billing" {
+		t.Errorf("unexpected resource: %v", req.resource)
+	}
+	expected := map[string]uint64{"app.requests": 7, "app.inflight": 5, "app.latency": 9, "app.size": 9}
+	for name, data := range expected {
+		if req.metrics[name] != data {
+			t.Errorf("expected data %d of %s, got metrics %v", data, name, req.metrics)
+		}
+	}
+}
+
+func TestOTLPHTTP(t *testing.T) {
+	received := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != otlpHTTPPath || r.Header.Get("Content-Type") != "application/x-protobuf" || r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		received <- b
+	}))
+	defer srv.Close()
+	reportOTLP(t, OTLPCfg{
+		Endpoint: srv.URL,
+		Headers:  map[string]string{"x-token": "secret"},
+		Resource: map[string]string{"service.name": "billing"},
+	})
+	select {
+	case b := <-received:
+		checkOTLPRequest(t, decodeOTLPRequest(t, b))
+	default:
+		t.Fatal("metrics are not exported")
+	}
+}
+
+// testCodec is rawCodec for grpc server
+type testCodec struct {
+	rawCodec
+}
+
+func (testCodec) String() string {
+	return "proto"
+}
+
+func TestOTLPGRPC(t *testing.T) {
+	received := make(chan []byte, 1)
+	srv := grpc.NewServer(grpc.CustomCodec(testCodec{}))
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "opentelemetry.proto.collector.metrics.v1.MetricsService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Export",
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var req []byte
+				if e := dec(&req); e != nil {
+					return nil, e
+				}
+				received <- req
+				return []byte{}, nil
+			},
+		}},
+	}, struct{}{})
+	ln, e := net.Listen("tcp", "127.0.0.1:0")
+	if e != nil {
+		t.Fatal(e)
+	}
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer srv.Stop()
+	reportOTLP(t, OTLPCfg{
+		Protocol: OTLPProtocolGRPC,
+		Endpoint: ln.Addr().String(),
+		Insecure: true,
+		Resource: map[string]string{"service.name": "billing"},
+	})
+	select {
+	case b := <-received:
+		checkOTLPRequest(t, decodeOTLPRequest(t, b))
+	default:
+		t.Fatal("metrics are not exported")
+	}
+}
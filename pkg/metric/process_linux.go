@@ -0,0 +1,70 @@
+//go:build linux
+// +build linux
+
+package metric
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// userHZ is a number of clock ticks per second used by /proc/[pid]/stat, it's 100 on all supported architectures
+const userHZ = 100
+
+// readProcessStats returns stats of current process from procfs
+func readProcessStats() (processStats, bool) {
+	var st processStats
+	b, e := ioutil.ReadFile("/proc/self/stat")
+	if e != nil {
+		return st, false
+	}
+	// fields after command name which can contain spaces and parentheses
+	i := bytes.LastIndexByte(b, ')')
+	if i < 0 {
+		return st, false
+	}
+	fields := strings.Fields(string(b[i+1:]))
+	// utime, stime, vsize and rss are 14, 15, 23 and 24 fields of stat, the first field after command is 3
+	if len(fields) < 22 {
+		return st, false
+	}
+	utime, _ := strconv.ParseInt(fields[11], 10, 64)
+	stime, _ := strconv.ParseInt(fields[12], 10, 64)
+	vsize, _ := strconv.ParseInt(fields[20], 10, 64)
+	rss, _ := strconv.ParseInt(fields[21], 10, 64)
+	st.cpuMillis = (utime + stime) * 1000 / userHZ
+	st.virtualMemory = vsize
+	st.residentMemory = rss * int64(os.Getpagesize())
+	if fds, e := ioutil.ReadDir("/proc/self/fd"); e == nil {
+		st.openFDs = len(fds)
+	}
+	st.maxFDs = readMaxFDs()
+	return st, true
+}
+
+// readMaxFDs returns soft limit of open files
+func readMaxFDs() int {
+	f, e := os.Open("/proc/self/limits")
+	if e != nil {
+		return 0
+	}
+	defer f.Close()
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 {
+			return 0
+		}
+		n, _ := strconv.Atoi(fields[0])
+		return n
+	}
+	return 0
+}
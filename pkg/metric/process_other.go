@@ -0,0 +1,9 @@
+//go:build !linux
+// +build !linux
+
+package metric
+
+// readProcessStats returns false, stats of process are collected on linux only
+func readProcessStats() (processStats, bool) {
+	return processStats{}, false
+}
@@ -0,0 +1,159 @@
+package metric
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+	"github.com/pkg/errors"
+	promreporter "github.com/uber-go/tally/prometheus"
+)
+
+// prometheusServer is http server of prometheus metrics, it's shut down gracefully when context is done
+type prometheusServer struct {
+	server          *http.Server
+	shutdownTimeout time.Duration
+	once            sync.Once
+	done            chan struct{}
+	err             error
+}
+
+// newPrometheusReporter returns reporter serves metrics on configured address,
+// closer shuts down server, it's also called when context is done
+func newPrometheusReporter(ctx context.Context, log logger.Logger, cfg PrometheusCfg) (promreporter.Reporter, io.Closer, error) {
+	r := promreporter.NewReporter(prometheusOptions(log, cfg))
+	s, e := newPrometheusServer(ctx, log, cfg, r.HTTPHandler())
+	if e != nil {
+		return nil, nil, e
+	}
+	return r, s, nil
+}
+
+// prometheusOptions returns options of reporter, errors of registration are logged instead of panic,
+// reporter gets own registry with go and process collectors if it's configured and neither registerer nor gatherer is set
+func prometheusOptions(log logger.Logger, cfg PrometheusCfg) promreporter.Options {
+	opts := cfg.Options
+	if cfg.OwnRegistry && opts.Registerer == nil && opts.Gatherer == nil {
+		registry := prom.NewRegistry()
+		registry.MustRegister(prom.NewGoCollector(), prom.NewProcessCollector(os.Getpid(), ""))
+		opts.Registerer, opts.Gatherer = registry, registry
+	}
+	if opts.OnRegisterError == nil {
+		opts.OnRegisterError = func(e error) {
+			log.Error("%v", logger.Args(errors.WithMessage(e, Prefix+": prometheus")))
+		}
+	}
+	return opts
+}
+
+// newPrometheusServer starts server of handler on configured address
+func newPrometheusServer(ctx context.Context, log logger.Logger, cfg PrometheusCfg, h http.Handler) (*prometheusServer, error) {
+	address := cfg.Address
+	if address == "" {
+		address = defaultPrometheusAddress
+	}
+	u, e := url.Parse(address)
+	if e != nil {
+		return nil, errors.WithMessage(e, Prefix)
+	}
+	tlsCfg, e := serverTLS(cfg)
+	if e != nil {
+		return nil, e
+	}
+	ln, e := net.Listen("tcp", u.Host)
+	if e != nil {
+		return nil, errors.WithMessage(e, Prefix)
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, basicAuth(h, cfg.Username, cfg.Password))
+	s := &prometheusServer{
+		server: &http.Server{
+			Handler:      mux,
+			TLSConfig:    tlsCfg,
+			ReadTimeout:  durationOr(cfg.ReadTimeout, defaultReadTimeout),
+			WriteTimeout: durationOr(cfg.WriteTimeout, defaultWriteTimeout),
+		},
+		shutdownTimeout: durationOr(cfg.ShutdownTimeout, defaultShutdownTimeout),
+		done:            make(chan struct{}),
+	}
+	go func() {
+		var err error
+		if tlsCfg != nil {
+			err = s.server.ServeTLS(ln, "", "")
+		} else {
+			err = s.server.Serve(ln)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			log.Error("%v", logger.Args(errors.WithMessage(err, Prefix+": prometheus")))
+		}
+	}()
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = s.Close()
+		case <-s.done:
+		}
+	}()
+	return s, nil
+}
+
+// serverTLS returns tls config of server if certificate is set, invalid certificate fails start instead of serving
+func serverTLS(cfg PrometheusCfg) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+	cert, e := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if e != nil {
+		return nil, errors.WithMessage(e, Prefix)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// Close shuts down server waiting for active requests
+func (s *prometheusServer) Close() error {
+	s.once.Do(func() {
+		close(s.done)
+		ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		s.err = s.server.Shutdown(ctx)
+	})
+	return s.err
+}
+
+// basicAuth returns handler checks credentials if username is set
+func basicAuth(h http.Handler, username, password string) http.Handler {
+	if username == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u, p, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(u), []byte(username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func durationOr(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
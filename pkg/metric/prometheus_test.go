@@ -0,0 +1,235 @@
+package metric
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/config"
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	prom "github.com/m3db/prometheus_client_golang/prometheus"
+	promreporter "github.com/uber-go/tally/prometheus"
+)
+
+func freeAddress(t *testing.T) string {
+	ln, e := net.Listen("tcp", "127.0.0.1:0")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func TestPrometheusServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	log := logger.NewMock(ctx, &logger.Config{}, false)
+	addr := freeAddress(t)
+	registry := prom.NewRegistry()
+	cfg := PrometheusCfg{
+		Address:  "http://" + addr + "/metrics",
+		Options:  promreporter.Options{Registerer: registry, Gatherer: registry},
+		Username: "user",
+		Password: "secret",
+	}
+	_, _, e := newPrometheusReporter(ctx, log, cfg)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	if _, _, e := newPrometheusReporter(ctx, log, cfg); e == nil {
+		t.Error("bind error is not returned")
+	}
+
+	get := func(username, password string) (int, error) {
+		req, _ := http.NewRequest(http.MethodGet, cfg.Address, nil)
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		resp, e := http.DefaultClient.Do(req)
+		if e != nil {
+			return 0, e
+		}
+		resp.Body.Close()
+		return resp.StatusCode, nil
+	}
+	if code, e := get("user", "secret"); e != nil || code != http.StatusOK {
+		t.Errorf("expected 200 with valid credentials, got %d %v", code, e)
+	}
+	if code, e := get("user", "wrong"); e != nil || code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with invalid credentials, got %d %v", code, e)
+	}
+
+	cancel()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if _, e := get("user", "secret"); e != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("server is not shut down")
+		}
+	}
+}
+
+func TestPrometheusReporters(t *testing.T) {
+	inv := invoker.NewInvoker()
+	initial := config.Initial{Viper: config.NewViper()}
+	first, second := "http://"+freeAddress(t)+"/metrics", "http://"+freeAddress(t)+"/metrics"
+	initial.Viper.Set("metric.enabled", true)
+	initial.Viper.Set("metric.interval", "10ms")
+	initial.Viper.Set("metric.reporters", []interface{}{
+		map[string]interface{}{"type": ReporterPrometheus, "prometheus": map[string]interface{}{"address": first, "ownRegistry": true}},
+		map[string]interface{}{"type": ReporterPrometheus, "prometheus": map[string]interface{}{"address": second, "ownRegistry": true}},
+	})
+	configurator, _, e := config.Provider(initial, inv)
+	if e != nil {
+		t.Fatal(e)
+	}
+	cfg, _, e := ProviderCfg(configurator)
+	if e != nil {
+		t.Fatal(e)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan string, 10)
+	log := logger.NewMockFunc(ctx, &logger.Config{}, func(e logger.Entity) {
+		if e.Level == logger.LevelError {
+			errs <- fmt.Sprint(e.Args...)
+		}
+	})
+	scope, _, e := Provider(ctx, log, cfg)
+	if e != nil {
+		t.Fatal(e)
+	}
+	scope.Counter("requests").Inc(1)
+
+	expect := func(address, metric string) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			if resp, e := http.Get(address); e == nil {
+				b, _ := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				if strings.Contains(string(b), metric) {
+					return
+				}
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("metric %s isn't served by %s", metric, address)
+			}
+		}
+	}
+	for _, address := range []string{first, second} {
+		expect(address, "requests 1")
+		expect(address, "go_goroutines")
+	}
+
+	// reporters are kept on reload, metric with changed tag keys can't be registered again
+	root := reloadRootOf(scope)
+	id := root.load().id
+	initial.Viper.Set("metric.scope.tags", map[string]string{"env": "test"})
+	inv.Reload(ctx)
+	for deadline := time.Now().Add(5 * time.Second); root.load().id == id; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("metric config is not reloaded")
+		}
+	}
+	scope.Counter("requests").Inc(1)
+	select {
+	case e := <-errs:
+		if !strings.Contains(e, "prometheus") {
+			t.Errorf("unexpected error: %s", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("error of registration isn't logged")
+	}
+}
+
+func TestPrometheusDefaultRegistry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	collector := prom.NewCounter(prom.CounterOpts{Name: "application_events", Help: "events of application"})
+	if e := prom.Register(collector); e != nil {
+		t.Fatal(e)
+	}
+	defer prom.Unregister(collector)
+	collector.Inc()
+	cfg := PrometheusCfg{Address: "http://" + freeAddress(t) + "/metrics"}
+	if _, _, e := newPrometheusReporter(ctx, logger.NewMock(ctx, &logger.Config{}, true), cfg); e != nil {
+		t.Fatal(e)
+	}
+	resp, e := http.Get(cfg.Address)
+	if e != nil {
+		t.Fatal(e)
+	}
+	b, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(b), "application_events 1") {
+		t.Errorf("collector of default registry isn't served: %s", b)
+	}
+}
+
+func TestPrometheusInvalidCert(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	addr := freeAddress(t)
+	cfg := PrometheusCfg{Address: "http://" + addr + "/metrics", CertFile: "missing.pem", KeyFile: "missing.key"}
+	if _, _, e := newPrometheusReporter(ctx, logger.NewMock(ctx, &logger.Config{}, true), cfg); e == nil {
+		t.Fatal("error of certificate isn't returned")
+	}
+	ln, e := net.Listen("tcp", addr)
+	if e != nil {
+		t.Fatalf("address is bound by failed server: %v", e)
+	}
+	_ = ln.Close()
+
+	// previous generation is kept on reload with invalid certificate
+	inv := invoker.NewInvoker()
+	initial := config.Initial{Viper: config.NewViper()}
+	initial.Viper.Set("metric.enabled", true)
+	initial.Viper.Set("metric.reporters", []interface{}{
+		map[string]interface{}{"type": ReporterPrometheus, "prometheus": map[string]interface{}{"address": cfg.Address}},
+	})
+	configurator, _, e := config.Provider(initial, inv)
+	if e != nil {
+		t.Fatal(e)
+	}
+	metricCfg, _, e := ProviderCfg(configurator)
+	if e != nil {
+		t.Fatal(e)
+	}
+	errs := make(chan string, 10)
+	log := logger.NewMockFunc(ctx, &logger.Config{}, func(e logger.Entity) {
+		if e.Level == logger.LevelError {
+			errs <- fmt.Sprint(e.Args...)
+		}
+	})
+	scope, _, e := Provider(ctx, log, metricCfg)
+	if e != nil {
+		t.Fatal(e)
+	}
+	initial.Viper.Set("metric.reporters", []interface{}{
+		map[string]interface{}{"type": ReporterPrometheus, "prometheus": map[string]interface{}{
+			"address": cfg.Address, "certFile": cfg.CertFile, "keyFile": cfg.KeyFile,
+		}},
+	})
+	inv.Reload(ctx)
+	select {
+	case <-errs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("error of reload isn't logged")
+	}
+	if id := reloadRootOf(scope).load().id; id != 0 {
+		t.Errorf("generation is replaced by invalid config: %d", id)
+	}
+	resp, e := http.Get(cfg.Address)
+	if e != nil {
+		t.Fatalf("server of previous generation is shut down: %v", e)
+	}
+	resp.Body.Close()
+}
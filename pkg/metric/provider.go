@@ -5,12 +5,7 @@ import (
 	"github.com/ProtocolONE/go-core/v2/pkg/config"
 	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
-	"github.com/cactus/go-statsd-client/statsd"
 	"github.com/google/wire"
-	promreporter "github.com/uber-go/tally/prometheus"
-	tallystatsd "github.com/uber-go/tally/statsd"
-	"net/http"
-	"net/url"
 	"sync"
 )
 
@@ -24,62 +19,63 @@ func ProviderCfg(cfg config.Configurator) (*Config, func(), error) {
 	c := &Config{
 		invoker: invoker.NewInvoker(),
 	}
-	e := cfg.UnmarshalKey(UnmarshalKey, c)
+	e := cfg.UnmarshalKeyOnReload(UnmarshalKey, c, DottedKeysHookFunc())
 	return c, func() {}, e
 }
 
-// Provider returns client metric instance implemented of Scope interface with resolved dependencies
+// Provider returns client metric instance implemented of Scope interface with resolved dependencies,
+// reporters are rebuilt on reload of config
 func Provider(ctx context.Context, log logger.Logger, cfg *Config) (Scope, func(), error) {
-	if !cfg.Enabled {
-		return ProviderTest()
-	}
-	statter, e := statsd.NewBufferedClient(
-		cfg.StatsD.Addr,
-		cfg.StatsD.Prefix,
-		cfg.StatsD.FlushInterval,
-		cfg.StatsD.FlushBytes,
-	)
+	m, e := newScope(ctx, log, cfg, false)
 	if e != nil {
 		return nil, nil, e
 	}
-	cfg.Scope.Reporter = tallystatsd.NewReporter(statter, cfg.StatsD.Options)
-	m := NewTally(ctx, log, cfg.Scope, cfg.Interval)
 	return m, func() {}, nil
 }
 
-// ProviderPrometheus returns prometheus connector metric instance implemented of Scope interface with resolved dependencies
+// ProviderPrometheus returns prometheus connector metric instance implemented of Scope interface with resolved dependencies,
+// reporters are rebuilt on reload of config
 func ProviderPrometheus(ctx context.Context, log logger.Logger, cfg *Config) (Scope, func(), error) {
 	defer mu.Unlock()
 	mu.Lock()
 	if m != nil {
 		return m, func() {}, nil
 	}
-	if !cfg.Enabled {
-		return ProviderTest()
-	}
-	u, e := url.Parse(cfg.Prometheus.Address)
+	scope, e := newScope(ctx, log, cfg, true)
 	if e != nil {
 		return nil, nil, e
 	}
-	cfgCopy := cfg
-	r := promreporter.NewReporter(cfgCopy.Prometheus.Options)
-	cfgCopy.Scope.Tags = map[string]string{}
-	cfgCopy.Scope.CachedReporter = r
-	if cfgCopy.Scope.Separator == "" {
-		cfgCopy.Scope.Separator = promreporter.DefaultSeparator
-	}
-	//
-	http.Handle(u.Path, r.HTTPHandler())
-	go func() {
-		err := http.ListenAndServe(u.Host, nil)
-		if err != nil {
-			panic(err)
-		}
-	}()
-	m = NewTally(ctx, log, cfgCopy.Scope, cfgCopy.Interval)
+	m = scope
 	return m, func() {}, nil
 }
 
+// newScope returns reloadable scope guarded by limits of unique combinations of tags, disabled guard passes
+// all combinations, so it can be enabled on reload. Guard and root are updated by single subscriber,
+// so reload is done once root is rebuilt
+func newScope(ctx context.Context, log logger.Logger, cfg *Config, prometheus bool) (Scope, error) {
+	r, e := newReloadRoot(ctx, log, cfg, prometheus)
+	if e != nil {
+		return nil, e
+	}
+	root := &reloadScope{root: r}
+	g := newGuard(log, root, cfg.Cardinality)
+	cfg.OnReload(func(_ context.Context) {
+		c := cfg.loaded()
+		g.update(c.Cardinality)
+		r.reload(c)
+	})
+	guarded := &guardScope{guard: g, base: root}
+	collectRuntime(ctx, guarded, cfg)
+	return guarded, nil
+}
+
+// collectRuntime starts collector of runtime metrics if it's enabled
+func collectRuntime(ctx context.Context, m Scope, cfg *Config) {
+	if cfg.Runtime.Enabled {
+		CollectRuntime(ctx, m, cfg.Runtime)
+	}
+}
+
 // ProviderTest returns stub/mock client metric instance implemented of Scope interface with resolved dependencies
 func ProviderTest() (Scope, func(), error) {
 	m := NewMock()
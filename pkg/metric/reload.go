@@ -0,0 +1,444 @@
+package metric
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/pkg/errors"
+	"github.com/uber-go/tally"
+	promreporter "github.com/uber-go/tally/prometheus"
+)
+
+// generation is root scope built by config, it's replaced by new one on reload
+type generation struct {
+	id     uint64
+	scope  Scope
+	closer io.Closer
+	// writers is a count of metric calls in progress, generation is closed after them
+	writers int64
+	// waiting is set when generation is replaced, the last of calls in progress signals done then
+	waiting int32
+	mu      sync.Mutex
+	done    *sync.Cond
+}
+
+func newGeneration(id uint64, scope Scope, closer io.Closer) *generation {
+	g := &generation{id: id, scope: scope, closer: closer}
+	g.done = sync.NewCond(&g.mu)
+	return g
+}
+
+// release finishes metric call by generation
+func (g *generation) release() {
+	if atomic.AddInt64(&g.writers, -1) == 0 && atomic.LoadInt32(&g.waiting) == 1 {
+		g.mu.Lock()
+		g.done.Broadcast()
+		g.mu.Unlock()
+	}
+}
+
+// wait waits for metric calls in progress, generation must be replaced before, so new calls don't use it
+func (g *generation) wait() {
+	atomic.StoreInt32(&g.waiting, 1)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for atomic.LoadInt64(&g.writers) > 0 {
+		g.done.Wait()
+	}
+}
+
+// resolved is metric or scope of generation
+type resolved struct {
+	id    uint64
+	value interface{}
+}
+
+// reloadRoot keeps generation of root scope, root scope is rebuilt on reload of config
+type reloadRoot struct {
+	ctx        context.Context
+	log        logger.Logger
+	prometheus bool
+	reporters  *prometheusReporters
+	mu         sync.Mutex
+	gen        atomic.Value
+}
+
+// NewReloadable returns scope built by config, root scope is rebuilt on reload of config,
+// metrics got before reload are reported by new root scope, previous one is closed with reporting of in-flight values
+func NewReloadable(ctx context.Context, log logger.Logger, cfg *Config) (Scope, error) {
+	r, e := newReloadRoot(ctx, log, cfg, false)
+	if e != nil {
+		return nil, e
+	}
+	cfg.OnReload(func(_ context.Context) {
+		r.reload(cfg.loaded())
+	})
+	return &reloadScope{root: r}, nil
+}
+
+// newReloadRoot returns root built by config, prometheus is used as default reporter instead of statsd if it's set,
+// caller rebuilds root on reload of config
+func newReloadRoot(ctx context.Context, log logger.Logger, cfg *Config, prometheus bool) (*reloadRoot, error) {
+	r := &reloadRoot{
+		ctx:        ctx,
+		log:        log.WithFields(logger.Fields{"service": Prefix}),
+		prometheus: prometheus,
+	}
+	r.reporters = &prometheusReporters{ctx: ctx, log: r.log}
+	scope, closer, e := r.build(cfg)
+	if e != nil {
+		r.reporters.Close()
+		return nil, e
+	}
+	r.gen.Store(newGeneration(0, scope, closer))
+	if ma, ok := log.(logger.MetricAware); ok {
+		ma.SetMetric((&reloadScope{root: r}).SubScope(MetricLoggerScope))
+	}
+	go func() {
+		<-ctx.Done()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.close(r.load())
+		r.reporters.Close()
+	}()
+	return r, nil
+}
+
+func (r *reloadRoot) load() *generation {
+	return r.gen.Load().(*generation)
+}
+
+// acquire returns current generation for metric call, it's not closed by reload until call releases it
+func (r *reloadRoot) acquire() *generation {
+	for {
+		gen := r.load()
+		atomic.AddInt64(&gen.writers, 1)
+		if r.load() == gen {
+			return gen
+		}
+		// generation is replaced meanwhile, so it can be closed already
+		gen.release()
+	}
+}
+
+// build returns root scope and its closer by config
+func (r *reloadRoot) build(cfg *Config) (Scope, io.Closer, error) {
+	r.reporters.next = 0
+	switch {
+	case !cfg.Enabled:
+		return NewMock(), multiCloser{}, nil
+	case len(cfg.Reporters) > 0:
+		return newReportersScope(r.log, cfg, r.reporters.get)
+	case r.prometheus:
+		reporter, _, e := r.reporters.get(cfg.Prometheus)
+		if e != nil {
+			return nil, nil, e
+		}
+		opts := cfg.Scope
+		opts.Tags = map[string]string{}
+		opts.CachedReporter = reporter
+		if opts.Separator == "" {
+			opts.Separator = promreporter.DefaultSeparator
+		}
+		scope, closer := tally.NewRootScope(opts, cfg.Interval)
+		return scope, closer, nil
+	}
+	reporter, e := newStatsDReporter(cfg.StatsD)
+	if e != nil {
+		return nil, nil, e
+	}
+	opts := cfg.Scope
+	opts.Reporter = reporter
+	scope, closer := tally.NewRootScope(opts, cfg.Interval)
+	return scope, closer, nil
+}
+
+// reload replaces generation by new one built by config, current generation is kept if config is invalid
+func (r *reloadRoot) reload(cfg *Config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ctx.Err() != nil {
+		return
+	}
+	scope, closer, e := r.build(cfg)
+	if e != nil {
+		r.log.Error("%v", logger.Args(errors.WithMessage(e, Prefix+": reload")))
+		return
+	}
+	r.reporters.sweep()
+	r.replace(scope, closer)
+}
+
+// replace stores new generation, previous one is closed after metric calls in progress, so their values are reported
+func (r *reloadRoot) replace(scope Scope, closer io.Closer) {
+	old := r.load()
+	r.gen.Store(newGeneration(old.id+1, scope, closer))
+	old.wait()
+	r.close(old)
+}
+
+// close closes root scope of generation, last values are reported on close
+func (r *reloadRoot) close(gen *generation) {
+	if e := gen.closer.Close(); e != nil {
+		r.log.Error("%v", logger.Args(e))
+	}
+}
+
+// reloadScope is Scope resolves metrics by root scope of current generation
+type reloadScope struct {
+	root   *reloadRoot
+	parent *reloadScope
+	name   string
+	tags   map[string]string
+	cache  atomic.Value
+}
+
+// resolve returns scope of generation
+func (s *reloadScope) resolve(gen *generation) Scope {
+	if v, ok := cached(&s.cache, gen); ok {
+		return v.(Scope)
+	}
+	scope := gen.scope
+	if s.parent != nil {
+		scope = s.parent.resolve(gen)
+		if s.tags != nil {
+			scope = scope.Tagged(s.tags)
+		} else {
+			scope = scope.SubScope(s.name)
+		}
+	}
+	s.cache.Store(resolved{id: gen.id, value: scope})
+	return scope
+}
+
+// cached returns value cached for generation
+func cached(cache *atomic.Value, gen *generation) (interface{}, bool) {
+	if v, ok := cache.Load().(resolved); ok && v.id == gen.id {
+		return v.value, true
+	}
+	return nil, false
+}
+
+// Counter returns the Counter object corresponding to the name.
+func (s *reloadScope) Counter(name string) Counter {
+	return &reloadCounter{scope: s, name: name}
+}
+
+// Gauge returns the Gauge object corresponding to the name.
+func (s *reloadScope) Gauge(name string) Gauge {
+	return &reloadGauge{scope: s, name: name}
+}
+
+// Timer returns the Timer object corresponding to the name.
+func (s *reloadScope) Timer(name string) Timer {
+	return &reloadTimer{scope: s, name: name}
+}
+
+// Histogram returns the Histogram object corresponding to the name.
+func (s *reloadScope) Histogram(name string, buckets Buckets) Histogram {
+	return &reloadHistogram{scope: s, name: name, buckets: buckets}
+}
+
+// Tagged returns a new child scope with the given tags and current tags.
+func (s *reloadScope) Tagged(tags map[string]string) Scope {
+	copied := make(map[string]string, len(tags))
+	for k, v := range tags {
+		copied[k] = v
+	}
+	return &reloadScope{root: s.root, parent: s, tags: copied}
+}
+
+// SubScope returns a new child scope appending a further name prefix.
+func (s *reloadScope) SubScope(name string) Scope {
+	return &reloadScope{root: s.root, parent: s, name: name}
+}
+
+// Capabilities returns a description of metrics reporting capabilities.
+func (s *reloadScope) Capabilities() Capabilities {
+	return s.resolve(s.root.load()).Capabilities()
+}
+
+type reloadCounter struct {
+	scope *reloadScope
+	name  string
+	cache atomic.Value
+}
+
+func (c *reloadCounter) counter(gen *generation) Counter {
+	if v, ok := cached(&c.cache, gen); ok {
+		return v.(Counter)
+	}
+	counter := c.scope.resolve(gen).Counter(c.name)
+	c.cache.Store(resolved{id: gen.id, value: counter})
+	return counter
+}
+
+// Inc increments the counter by a delta.
+func (c *reloadCounter) Inc(delta int64) {
+	gen := c.scope.root.acquire()
+	defer gen.release()
+	c.counter(gen).Inc(delta)
+}
+
+type reloadGauge struct {
+	scope *reloadScope
+	name  string
+	cache atomic.Value
+}
+
+func (g *reloadGauge) gauge(gen *generation) Gauge {
+	if v, ok := cached(&g.cache, gen); ok {
+		return v.(Gauge)
+	}
+	gauge := g.scope.resolve(gen).Gauge(g.name)
+	g.cache.Store(resolved{id: gen.id, value: gauge})
+	return gauge
+}
+
+// Update sets the gauges absolute value.
+func (g *reloadGauge) Update(value float64) {
+	gen := g.scope.root.acquire()
+	defer gen.release()
+	g.gauge(gen).Update(value)
+}
+
+type reloadTimer struct {
+	scope *reloadScope
+	name  string
+	cache atomic.Value
+}
+
+func (t *reloadTimer) timer(gen *generation) Timer {
+	if v, ok := cached(&t.cache, gen); ok {
+		return v.(Timer)
+	}
+	timer := t.scope.resolve(gen).Timer(t.name)
+	t.cache.Store(resolved{id: gen.id, value: timer})
+	return timer
+}
+
+// Record a specific duration directly.
+func (t *reloadTimer) Record(value time.Duration) {
+	gen := t.scope.root.acquire()
+	defer gen.release()
+	t.timer(gen).Record(value)
+}
+
+// Start gives you back a specific point in time to report via Stop.
+func (t *reloadTimer) Start() Stopwatch {
+	return tally.NewStopwatch(time.Now(), t)
+}
+
+// RecordStopwatch records duration by timer of generation is current on stop
+func (t *reloadTimer) RecordStopwatch(stopwatchStart time.Time) {
+	t.Record(time.Since(stopwatchStart))
+}
+
+type reloadHistogram struct {
+	scope   *reloadScope
+	name    string
+	buckets Buckets
+	cache   atomic.Value
+}
+
+func (h *reloadHistogram) histogram(gen *generation) Histogram {
+	if v, ok := cached(&h.cache, gen); ok {
+		return v.(Histogram)
+	}
+	histogram := h.scope.resolve(gen).Histogram(h.name, h.buckets)
+	h.cache.Store(resolved{id: gen.id, value: histogram})
+	return histogram
+}
+
+// RecordValue records a specific value directly.
+func (h *reloadHistogram) RecordValue(value float64) {
+	gen := h.scope.root.acquire()
+	defer gen.release()
+	h.histogram(gen).RecordValue(value)
+}
+
+// RecordDuration records a specific duration directly.
+func (h *reloadHistogram) RecordDuration(value time.Duration) {
+	gen := h.scope.root.acquire()
+	defer gen.release()
+	h.histogram(gen).RecordDuration(value)
+}
+
+// Start gives you a specific point in time to then record a duration.
+func (h *reloadHistogram) Start() Stopwatch {
+	return tally.NewStopwatch(time.Now(), h)
+}
+
+// RecordStopwatch records duration by histogram of generation is current on stop
+func (h *reloadHistogram) RecordStopwatch(stopwatchStart time.Time) {
+	h.RecordDuration(time.Since(stopwatchStart))
+}
+
+// prometheusReporters keeps prometheus reporters of generations in order of configured reporters,
+// reporter is registered in prometheus registry once, only its server is restarted if settings of server are changed
+type prometheusReporters struct {
+	ctx     context.Context
+	log     logger.Logger
+	entries []*prometheusEntry
+	next    int
+}
+
+type prometheusEntry struct {
+	cfg      PrometheusCfg
+	reporter promreporter.Reporter
+	server   *prometheusServer
+}
+
+// get returns reporter for next prometheus reporter of generation, server is managed by prometheusReporters
+func (p *prometheusReporters) get(cfg PrometheusCfg) (promreporter.Reporter, io.Closer, error) {
+	if p.next == len(p.entries) {
+		p.entries = append(p.entries, &prometheusEntry{reporter: promreporter.NewReporter(prometheusOptions(p.log, cfg))})
+	}
+	entry := p.entries[p.next]
+	if entry.server == nil || !sameServer(entry.cfg, cfg) {
+		// server of previous settings is kept if certificate is invalid
+		if _, e := serverTLS(cfg); e != nil {
+			return nil, nil, e
+		}
+		if entry.server != nil {
+			_ = entry.server.Close()
+			entry.server = nil
+		}
+		server, e := newPrometheusServer(p.ctx, p.log, cfg, entry.reporter.HTTPHandler())
+		if e != nil {
+			return nil, nil, e
+		}
+		entry.cfg, entry.server = cfg, server
+	}
+	p.next++
+	return entry.reporter, nil, nil
+}
+
+// sweep shuts down servers of reporters aren't used by the last generation
+func (p *prometheusReporters) sweep() {
+	for _, entry := range p.entries[p.next:] {
+		if entry.server != nil {
+			_ = entry.server.Close()
+			entry.server = nil
+		}
+	}
+}
+
+// Close shuts down all servers
+func (p *prometheusReporters) Close() {
+	p.next = 0
+	p.sweep()
+}
+
+// sameServer returns true if settings of server are equal, options of reporter are applied only on start
+func sameServer(a, b PrometheusCfg) bool {
+	return a.Address == b.Address &&
+		a.CertFile == b.CertFile && a.KeyFile == b.KeyFile &&
+		a.Username == b.Username && a.Password == b.Password &&
+		a.ReadTimeout == b.ReadTimeout && a.WriteTimeout == b.WriteTimeout &&
+		a.ShutdownTimeout == b.ShutdownTimeout
+}
@@ -0,0 +1,225 @@
+package metric
+
+import (
+	"context"
+	"io"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/config"
+	"github.com/ProtocolONE/go-core/v2/pkg/invoker"
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/uber-go/tally"
+)
+
+// statsDListener collects packets received by udp
+type statsDListener struct {
+	conn    *net.UDPConn
+	packets chan string
+}
+
+func newStatsDListener(t *testing.T) *statsDListener {
+	conn, e := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if e != nil {
+		t.Fatal(e)
+	}
+	l := &statsDListener{conn: conn, packets: make(chan string, 100)}
+	go func() {
+		buf := make([]byte, 65536)
+		for {
+			n, e := conn.Read(buf)
+			if e != nil {
+				return
+			}
+			for _, line := range strings.Split(string(buf[:n]), "\n") {
+				if line != "" {
+					l.packets <- line
+				}
+			}
+		}
+	}()
+	return l
+}
+
+func (l *statsDListener) expect(t *testing.T, packet string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case p := <-l.packets:
+			if p == packet {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("packet %s is not received", packet)
+		}
+	}
+}
+
+func (l *statsDListener) expectNothing(t *testing.T, d time.Duration) {
+	t.Helper()
+	select {
+	case p := <-l.packets:
+		t.Fatalf("unexpected packet %s", p)
+	case <-time.After(d):
+	}
+}
+
+// reloadRootOf returns root of scope returned by provider
+func reloadRootOf(scope Scope) *reloadRoot {
+	if g, ok := scope.(*guardScope); ok {
+		scope = g.base
+	}
+	return scope.(*reloadScope).root
+}
+
+func TestReload(t *testing.T) {
+	first, second := newStatsDListener(t), newStatsDListener(t)
+	defer first.conn.Close()
+	defer second.conn.Close()
+
+	inv := invoker.NewInvoker()
+	initial := config.Initial{Viper: config.NewViper()}
+	initial.Viper.Set("metric.enabled", true)
+	initial.Viper.Set("metric.interval", "1h")
+	initial.Viper.Set("metric.statsD.addr", first.conn.LocalAddr().String())
+	initial.Viper.Set("metric.statsD.prefix", "first")
+	initial.Viper.Set("metric.statsD.flushInterval", "10ms")
+	configurator, _, e := config.Provider(initial, inv)
+	if e != nil {
+		t.Fatal(e)
+	}
+	cfg, _, e := ProviderCfg(configurator)
+	if e != nil {
+		t.Fatal(e)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scope, _, e := Provider(ctx, logger.NewMock(ctx, &logger.Config{}, true), cfg)
+	if e != nil {
+		t.Fatal(e)
+	}
+	counter := scope.SubScope("http").Counter("requests")
+	root := reloadRootOf(scope)
+	// guard and root are updated by single subscriber, so reload is done once root is rebuilt
+	reload := func() {
+		t.Helper()
+		id := root.load().id
+		inv.Reload(ctx)
+		for deadline := time.Now().Add(5 * time.Second); root.load().id == id; time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal("metric config is not reloaded")
+			}
+		}
+	}
+
+	// in-flight count is reported by previous reporter on reload
+	counter.Inc(2)
+	goroutines := runtime.NumGoroutine()
+	initial.Viper.Set("metric.statsD.addr", second.conn.LocalAddr().String())
+	initial.Viper.Set("metric.statsD.prefix", "second")
+	reload()
+	first.expect(t, "first.http.requests:2|c")
+
+	counter.Inc(3)
+	initial.Viper.Set("metric.enabled", false)
+	reload()
+	second.expect(t, "second.http.requests:3|c")
+
+	counter.Inc(4)
+	initial.Viper.Set("metric.enabled", true)
+	reload()
+	second.expectNothing(t, 100*time.Millisecond)
+
+	counter.Inc(5)
+	cancel()
+	second.expect(t, "second.http.requests:5|c")
+
+	for deadline := time.Now().Add(5 * time.Second); runtime.NumGoroutine() > goroutines; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutines are leaked on reload: %d, before %d", runtime.NumGoroutine(), goroutines)
+		}
+	}
+}
+
+func TestReloadInFlight(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		reported int64
+		scopes   []tally.TestScope
+	)
+	// closer counts values reported by generation on close, values written after close are lost
+	build := func() (Scope, io.Closer) {
+		scope := tally.NewTestScope("", nil)
+		scopes = append(scopes, scope)
+		return slowScope{scope}, closerFunc(func() error {
+			mu.Lock()
+			defer mu.Unlock()
+			if c, ok := scope.Snapshot().Counters()["requests+"]; ok {
+				reported += c.Value()
+			}
+			return nil
+		})
+	}
+	scope, closer := build()
+	r := &reloadRoot{log: logger.NewMock(context.Background(), &logger.Config{}, true)}
+	r.gen.Store(newGeneration(0, scope, closer))
+	counter := (&reloadScope{root: r}).Counter("requests")
+
+	const writers, calls = 8, 2000
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				counter.Inc(1)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for reloading := true; reloading; {
+		select {
+		case <-done:
+			reloading = false
+		case <-time.After(100 * time.Microsecond):
+			r.replace(build())
+		}
+	}
+	r.close(r.load())
+	if reported != writers*calls {
+		t.Fatalf("values are lost on reload: reported %d of %d by %d generations", reported, writers*calls, len(scopes))
+	}
+}
+
+// slowScope returns counters yield before increment, so reload likely happens during increment
+type slowScope struct {
+	tally.Scope
+}
+
+func (s slowScope) Counter(name string) Counter {
+	return slowCounter{s.Scope.Counter(name)}
+}
+
+type slowCounter struct {
+	Counter
+}
+
+func (c slowCounter) Inc(delta int64) {
+	runtime.Gosched()
+	c.Counter.Inc(delta)
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
@@ -0,0 +1,143 @@
+package metric
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ProtocolONE/go-core/v2/pkg/logger"
+	"github.com/cactus/go-statsd-client/statsd"
+	"github.com/pkg/errors"
+	"github.com/uber-go/tally"
+	promreporter "github.com/uber-go/tally/prometheus"
+	tallystatsd "github.com/uber-go/tally/statsd"
+)
+
+// prometheusFunc returns prometheus reporter by settings, closer of reporter is nil if it's managed by caller
+type prometheusFunc func(cfg PrometheusCfg) (promreporter.Reporter, io.Closer, error)
+
+// NewReporters returns scope fans metrics out to all configured reporters, every reporter has own root scope
+func NewReporters(ctx context.Context, log logger.Logger, cfg *Config) (Scope, error) {
+	scope, closer, e := newReportersScope(log, cfg, func(cfg PrometheusCfg) (promreporter.Reporter, io.Closer, error) {
+		return newPrometheusReporter(ctx, log, cfg)
+	})
+	if e != nil {
+		return nil, e
+	}
+	return watchScope(ctx, log, scope, closer), nil
+}
+
+// newReportersScope returns scope fans metrics out to all configured reporters and closer of their root scopes
+func newReportersScope(log logger.Logger, cfg *Config, prometheus prometheusFunc) (Scope, io.Closer, error) {
+	var (
+		scopes  = make([]tally.Scope, 0, len(cfg.Reporters))
+		closers = make([]io.Closer, 0, len(cfg.Reporters))
+	)
+	for i, r := range cfg.Reporters {
+		scope, closer, e := newReporterScope(log, cfg, r, prometheus)
+		if e != nil {
+			for _, c := range closers {
+				_ = c.Close()
+			}
+			return nil, nil, errors.WithMessage(e, fmt.Sprintf("%s: reporter #%d %s", Prefix, i, r.Type))
+		}
+		scopes = append(scopes, scope)
+		closers = append(closers, closer)
+	}
+	return NewMultiScope(scopes...), multiCloser(closers), nil
+}
+
+// newReporterScope returns root scope of reporter
+func newReporterScope(log logger.Logger, cfg *Config, r ReporterCfg, prometheus prometheusFunc) (tally.Scope, io.Closer, error) {
+	opts := tally.ScopeOptions{
+		Tags:            map[string]string{},
+		Prefix:          r.Prefix,
+		Separator:       r.Separator,
+		SanitizeOptions: r.SanitizeOptions,
+		DefaultBuckets:  cfg.Scope.DefaultBuckets,
+	}
+	for k, v := range cfg.Scope.Tags {
+		opts.Tags[k] = v
+	}
+	for k, v := range r.Tags {
+		opts.Tags[k] = v
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = cfg.Scope.Prefix
+	}
+	if opts.Separator == "" {
+		opts.Separator = cfg.Scope.Separator
+	}
+	if opts.SanitizeOptions == nil {
+		opts.SanitizeOptions = cfg.Scope.SanitizeOptions
+	}
+	interval := r.Interval
+	if interval == 0 {
+		interval = cfg.Interval
+	}
+	var server io.Closer
+	switch r.Type {
+	case ReporterStatsD:
+		reporter, e := newStatsDReporter(r.StatsD)
+		if e != nil {
+			return nil, nil, e
+		}
+		opts.Reporter = reporter
+	case ReporterPrometheus:
+		reporter, closer, e := prometheus(r.Prometheus)
+		if e != nil {
+			return nil, nil, e
+		}
+		opts.CachedReporter, server = reporter, closer
+		if opts.Separator == "" {
+			opts.Separator = promreporter.DefaultSeparator
+		}
+	case ReporterOTLP:
+		reporter, e := NewOTLPReporter(log, r.OTLP)
+		if e != nil {
+			return nil, nil, e
+		}
+		opts.Reporter = reporter
+	default:
+		return nil, nil, ErrUnknownReporter
+	}
+	scope, closer := tally.NewRootScope(opts, interval)
+	if server != nil {
+		return scope, multiCloser{closer, server}, nil
+	}
+	return scope, closer, nil
+}
+
+// statsDReporter is reporter sends metrics into statsd, client is closed with root scope
+type statsDReporter struct {
+	tally.StatsReporter
+	statter statsd.Statter
+}
+
+// newStatsDReporter returns reporter sends metrics into statsd
+func newStatsDReporter(cfg StatsDCfg) (tally.StatsReporter, error) {
+	statter, e := statsd.NewBufferedClient(cfg.Addr, cfg.Prefix, cfg.FlushInterval, cfg.FlushBytes)
+	if e != nil {
+		return nil, e
+	}
+	return &statsDReporter{StatsReporter: tallystatsd.NewReporter(statter, cfg.Options), statter: statter}, nil
+}
+
+// Close flushes buffered metrics and closes statsd client
+func (r *statsDReporter) Close() error {
+	return r.statter.Close()
+}
+
+// multiCloser closes all closers in order, the first error is returned
+type multiCloser []io.Closer
+
+// Close
+func (c multiCloser) Close() error {
+	var err error
+	for _, closer := range c {
+		if e := closer.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package metric
+
+import (
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/uber-go/tally"
+)
+
+const (
+	MetricRuntimeScope = "runtime"
+	MetricProcessScope = "process"
+
+	defaultRuntimeInterval = 10 * time.Second
+)
+
+// RuntimeCfg is a setting of collector of go runtime and process metrics
+type RuntimeCfg struct {
+	Enabled bool
+	// Interval of collecting, default is used if not set
+	Interval time.Duration
+}
+
+// gcPauseBuckets are buckets of histogram of GC pauses
+var gcPauseBuckets = tally.DurationBuckets{
+	10 * time.Microsecond, 50 * time.Microsecond, 100 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond,
+}
+
+// runtimeCollector reports go runtime and process metrics into scope
+type runtimeCollector struct {
+	runtime Scope
+	process Scope
+	threads *pprof.Profile
+	// previous values of cumulative stats for reporting deltas
+	numGC      uint32
+	mallocs    uint64
+	frees      uint64
+	totalAlloc uint64
+	cpuMillis  int64
+}
+
+// CollectRuntime reports runtime and process metrics into scope periodically until context is done
+func CollectRuntime(ctx context.Context, scope Scope, cfg RuntimeCfg) {
+	c := newRuntimeCollector(scope)
+	ticker := time.NewTicker(durationOr(cfg.Interval, defaultRuntimeInterval))
+	go func() {
+		defer ticker.Stop()
+		for {
+			c.collect()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func newRuntimeCollector(scope Scope) *runtimeCollector {
+	return &runtimeCollector{
+		runtime: scope.SubScope(MetricRuntimeScope),
+		process: scope.SubScope(MetricProcessScope),
+		threads: pprof.Lookup("threadcreate"),
+	}
+}
+
+func (c *runtimeCollector) collect() {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	r := c.runtime
+	r.Gauge("goroutines").Update(float64(runtime.NumGoroutine()))
+	r.Gauge("threads").Update(float64(c.threads.Count()))
+	r.Gauge("heap_alloc").Update(float64(ms.HeapAlloc))
+	r.Gauge("heap_sys").Update(float64(ms.HeapSys))
+	r.Gauge("heap_idle").Update(float64(ms.HeapIdle))
+	r.Gauge("heap_inuse").Update(float64(ms.HeapInuse))
+	r.Gauge("heap_released").Update(float64(ms.HeapReleased))
+	r.Gauge("heap_objects").Update(float64(ms.HeapObjects))
+	r.Gauge("stack_inuse").Update(float64(ms.StackInuse))
+	r.Gauge("sys").Update(float64(ms.Sys))
+	r.Gauge("next_gc").Update(float64(ms.NextGC))
+	r.Gauge("gc_cpu_fraction").Update(ms.GCCPUFraction)
+	r.Counter("mallocs").Inc(int64(ms.Mallocs - c.mallocs))
+	r.Counter("frees").Inc(int64(ms.Frees - c.frees))
+	r.Counter("total_alloc").Inc(int64(ms.TotalAlloc - c.totalAlloc))
+	r.Counter("gc_count").Inc(int64(ms.NumGC - c.numGC))
+	c.mallocs, c.frees, c.totalAlloc = ms.Mallocs, ms.Frees, ms.TotalAlloc
+	// pauses of GC cycles since previous collecting, the buffer keeps the last 256 ones
+	pauses := r.Histogram("gc_pause", gcPauseBuckets)
+	n := ms.NumGC - c.numGC
+	if n > uint32(len(ms.PauseNs)) {
+		n = uint32(len(ms.PauseNs))
+	}
+	for i := uint32(0); i < n; i++ {
+		pauses.RecordDuration(time.Duration(ms.PauseNs[(ms.NumGC-i+255)%256]))
+	}
+	c.numGC = ms.NumGC
+	c.collectProcess()
+}
+
+// collectProcess reports stats of process if they are supported by platform
+func (c *runtimeCollector) collectProcess() {
+	st, ok := readProcessStats()
+	if !ok {
+		return
+	}
+	p := c.process
+	p.Gauge("open_fds").Update(float64(st.openFDs))
+	if st.maxFDs > 0 {
+		p.Gauge("max_fds").Update(float64(st.maxFDs))
+	}
+	p.Gauge("resident_memory").Update(float64(st.residentMemory))
+	p.Gauge("virtual_memory").Update(float64(st.virtualMemory))
+	p.Counter("cpu_ms").Inc(st.cpuMillis - c.cpuMillis)
+	c.cpuMillis = st.cpuMillis
+}
+
+// processStats are stats of current process
+type processStats struct {
+	openFDs        int
+	maxFDs         int
+	residentMemory int64
+	virtualMemory  int64
+	// cpuMillis is user and system time
+	cpuMillis int64
+}
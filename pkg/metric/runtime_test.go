@@ -0,0 +1,34 @@
+package metric
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/uber-go/tally"
+)
+
+func TestRuntimeCollector(t *testing.T) {
+	scope := tally.NewTestScope("app", nil)
+	c := newRuntimeCollector(scope)
+	c.collect()
+	runtime.GC()
+	c.collect()
+
+	snapshot := scope.Snapshot()
+	for _, name := range []string{"app.runtime.goroutines+", "app.runtime.heap_alloc+", "app.runtime.threads+"} {
+		if g, ok := snapshot.Gauges()[name]; !ok || g.Value() <= 0 {
+			t.Errorf("gauge %s is not reported", name)
+		}
+	}
+	if c, ok := snapshot.Counters()["app.runtime.gc_count+"]; !ok || c.Value() < 1 {
+		t.Errorf("gc count is not reported")
+	}
+	if h, ok := snapshot.Histograms()["app.runtime.gc_pause+"]; !ok || len(h.Durations()) == 0 {
+		t.Errorf("gc pauses are not reported")
+	}
+	if runtime.GOOS == "linux" {
+		if g, ok := snapshot.Gauges()["app.process.open_fds+"]; !ok || g.Value() <= 0 {
+			t.Errorf("open fds are not reported")
+		}
+	}
+}
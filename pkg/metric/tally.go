@@ -2,6 +2,7 @@ package metric
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/ProtocolONE/go-core/v2/pkg/logger"
@@ -10,12 +11,22 @@ import (
 
 // NewTally returns instance of uber/tally metric client implemented of Scope interface
 func NewTally(ctx context.Context, log logger.Logger, options tally.ScopeOptions, interval time.Duration) Scope {
-	log = log.WithFields(logger.Fields{"service": Prefix})
 	scope, closer := tally.NewRootScope(options, interval)
+	return watchScope(ctx, log, scope, closer)
+}
+
+// watchScope sets scope for own metrics of logger and closes root scopes when context is done
+func watchScope(ctx context.Context, log logger.Logger, scope Scope, closers ...io.Closer) Scope {
+	log = log.WithFields(logger.Fields{"service": Prefix})
+	if ma, ok := log.(logger.MetricAware); ok {
+		ma.SetMetric(scope.SubScope(MetricLoggerScope))
+	}
 	go func() {
 		<-ctx.Done()
-		if e := closer.Close(); e != nil {
-			log.Error("%v", logger.Args(e))
+		for _, closer := range closers {
+			if e := closer.Close(); e != nil {
+				log.Error("%v", logger.Args(e))
+			}
 		}
 	}()
 	return scope